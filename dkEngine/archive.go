@@ -0,0 +1,88 @@
+package dkEngine
+
+import (
+	"context"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// CopyFromContainer
+// 컨테이너의 path 를 tar 스트림으로 받는다, 정지된 컨테이너도 가능하며 사용 후 반드시 Close 해야 한다
+// ctx 가 종료되면 스트림도 끊긴다
+// tar 안의 경로는 path 의 마지막 이름부터 시작한다 ex) path=/data -> data/...
+func CopyFromContainer(
+	ctx context.Context,
+	host string,
+	id string,
+	path string,
+) (res io.ReadCloser, err error) {
+	var values = url.Values{}
+	values.Set("path", path)
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/containers/%s/archive?%s", host, id, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		res = resp.Body
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		_ = resp.Body.Close()
+		return
+	}
+}
+
+// CopyToContainer
+// tar 스트림을 컨테이너의 path 디렉토리에 푼다, 정지된 컨테이너도 가능하다
+func CopyToContainer(
+	ctx context.Context,
+	host string,
+	id string,
+	path string,
+	reader io.Reader,
+) (err error) {
+	var values = url.Values{}
+	values.Set("path", path)
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPut,
+		fmt.Sprintf("%s/containers/%s/archive?%s", host, id, values.Encode()),
+		reader,
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationTar)
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
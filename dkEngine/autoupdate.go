@@ -0,0 +1,246 @@
+package dkEngine
+
+import (
+	"context"
+	"github.com/d3v-friends/go-docker/dkReference"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"strings"
+	"time"
+)
+
+const (
+	// AutoUpdateLabel
+	// 자동 업데이트 대상 컨테이너의 라벨
+	// "true": 새 이미지가 있으면 업데이트한다
+	// "monitor": 업데이트 하지 않고 OnResult 로 알리기만 한다
+	AutoUpdateLabel        = "go-docker.auto-update"
+	AutoUpdateLabelEnable  = "true"
+	AutoUpdateLabelMonitor = "monitor"
+)
+
+type AutoUpdateAction string
+
+const (
+	AutoUpdateActionNone      AutoUpdateAction = "none"
+	AutoUpdateActionAvailable AutoUpdateAction = "available"
+	AutoUpdateActionUpdated   AutoUpdateAction = "updated"
+	AutoUpdateActionFailed    AutoUpdateAction = "failed"
+)
+
+type AutoUpdateResult struct {
+	ContainerId   string           `json:"containerId"`
+	ContainerName string           `json:"containerName"`
+	Image         string           `json:"image"`
+	CurrentDigest string           `json:"currentDigest"`
+	LatestDigest  string           `json:"latestDigest"`
+	Action        AutoUpdateAction `json:"action"`
+	// NewContainerId Action 이 updated 일 때 새로 생성된 컨테이너 id
+	NewContainerId string `json:"newContainerId,omitempty"`
+	Error          string `json:"error,omitempty"`
+}
+
+// AutoUpdateSchedule
+// last 이후 다음 확인 시각을 반환한다
+type AutoUpdateSchedule func(last time.Time) time.Time
+
+// ScheduleEvery
+// interval 마다 확인한다
+func ScheduleEvery(interval time.Duration) AutoUpdateSchedule {
+	return func(last time.Time) time.Time {
+		return last.Add(interval)
+	}
+}
+
+// ScheduleDaily
+// 매일 at (자정 기준, 로컬 시간) 에 확인한다 ex) ScheduleDaily(time.Hour * 4) 매일 04:00
+func ScheduleDaily(at time.Duration) AutoUpdateSchedule {
+	return func(last time.Time) time.Time {
+		var next = time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, last.Location()).Add(at)
+		if !next.After(last) {
+			next = next.AddDate(0, 0, 1)
+		}
+		return next
+	}
+}
+
+type AutoUpdateOptions struct {
+	// Schedule 기본값 ScheduleEvery(time.Hour)
+	Schedule AutoUpdateSchedule
+	// Deploy 컨테이너 교체에 사용한다, Registries 는 이미지의 레지스트리 주소로 선택하여 digest 조회, pull 에도 사용한다
+	// 호스트 포트가 고정된 컨테이너는 AllowDowntime 이 없으면 업데이트에 실패한다
+	Deploy *DeployOptions
+	// Cleanup 업데이트 후 이전 이미지를 삭제한다, 다른 컨테이너가 사용중이면 삭제하지 않는다
+	Cleanup bool
+	// OnResult 새 이미지가 있거나 업데이트, 실패한 컨테이너마다 호출된다
+	OnResult func(res *AutoUpdateResult)
+	// OnError 컨테이너 목록 조회 등 전체 확인이 실패했을 때 호출된다
+	OnError func(err error)
+}
+
+// AutoUpdate
+// ctx 가 종료될 때까지 Schedule 에 따라 CheckUpdates 를 반복한다
+// 확인이 실패해도 멈추지 않고 OnError 로 알린 후 다음 일정에 다시 확인한다
+func AutoUpdate(
+	ctx context.Context,
+	host string,
+	opts *AutoUpdateOptions,
+) (err error) {
+	opts = fnPointer.Default(opts, AutoUpdateOptions{})
+	var schedule = opts.Schedule
+	if schedule == nil {
+		schedule = ScheduleEvery(time.Hour)
+	}
+
+	var next = schedule(time.Now())
+	for {
+		var timer = time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			err = ctx.Err()
+			return
+		case <-timer.C:
+		}
+
+		if _, checkErr := CheckUpdates(ctx, host, opts); checkErr != nil && opts.OnError != nil {
+			opts.OnError(checkErr)
+		}
+
+		next = schedule(time.Now())
+	}
+}
+
+// CheckUpdates
+// AutoUpdateLabel 이 있는 실행중인 컨테이너의 이미지 digest 를 레지스트리의 digest 와 비교하여
+// 다르면 Deploy 로 같은 설정의 새 이미지 컨테이너로 교체한다
+// digest 로 고정된 이미지와 로컬에서 빌드한 이미지는 확인하지 않는다
+// 컨테이너별 실패는 결과의 Error 에 기록하고 계속 진행한다
+func CheckUpdates(
+	ctx context.Context,
+	host string,
+	opts *AutoUpdateOptions,
+) (ls []*AutoUpdateResult, err error) {
+	opts = fnPointer.Default(opts, AutoUpdateOptions{})
+
+	var containers Containers
+	if containers, err = QueryContainers(host); err != nil {
+		return
+	}
+
+	ls = make([]*AutoUpdateResult, 0)
+	for _, container := range containers {
+		if ctx.Err() != nil {
+			err = ctx.Err()
+			return
+		}
+
+		var mode = container.Labels[AutoUpdateLabel]
+		if container.State != "running" || (mode != AutoUpdateLabelEnable && mode != AutoUpdateLabelMonitor) {
+			continue
+		}
+
+		var res = checkUpdate(ctx, host, container.Id, mode == AutoUpdateLabelMonitor, opts)
+		ls = append(ls, res)
+
+		if res.Action != AutoUpdateActionNone && opts.OnResult != nil {
+			opts.OnResult(res)
+		}
+	}
+
+	return
+}
+
+func checkUpdate(
+	ctx context.Context,
+	host string,
+	id string,
+	monitor bool,
+	opts *AutoUpdateOptions,
+) (res *AutoUpdateResult) {
+	res = &AutoUpdateResult{
+		ContainerId: id,
+		Action:      AutoUpdateActionNone,
+	}
+
+	var err error
+	defer func() {
+		if err != nil {
+			res.Action = AutoUpdateActionFailed
+			res.Error = err.Error()
+		}
+	}()
+
+	var inspection *ContainerInspection
+	if inspection, err = inspect(ctx, host, id); err != nil {
+		return
+	}
+
+	res.ContainerName = strings.TrimPrefix(inspection.Name, "/")
+	res.Image = fnPointer.Default(inspection.Config, ContainerInspectionConfig{}).Image
+
+	var ref *dkReference.Reference
+	if ref, err = dkReference.Parse(res.Image); err != nil {
+		return
+	}
+
+	if ref.Digest != "" {
+		return
+	}
+
+	var image *ImageInspection
+	if image, err = InspectImage(host, inspection.Image); err != nil {
+		return
+	}
+
+	if res.CurrentDigest, err = image.RepoDigest(res.Image); err != nil || res.CurrentDigest == "" {
+		return
+	}
+
+	var deployOpts = *fnPointer.Default(opts.Deploy, DeployOptions{})
+	deployOpts.Registries = findRegistry(ref, deployOpts.Registries)
+
+	var distribution *DistributionInspection
+	if distribution, err = DistributionInspect(ctx, host, res.Image, deployOpts.Registries...); err != nil {
+		return
+	}
+
+	res.LatestDigest = distribution.Digest()
+	if res.LatestDigest == "" || res.LatestDigest == res.CurrentDigest {
+		return
+	}
+
+	res.Action = AutoUpdateActionAvailable
+	if monitor {
+		return
+	}
+
+	var deployed *DeployResult
+	if deployed, err = Deploy(ctx, host, NewCreateContainerArgsFromInspection(inspection, image), &deployOpts); err != nil {
+		return
+	}
+
+	res.Action = AutoUpdateActionUpdated
+	res.NewContainerId = deployed.Id
+
+	if opts.Cleanup {
+		// 다른 컨테이너가 사용중이면 삭제되지 않으며 이는 실패가 아니다
+		_, _ = RemoveImage(host, image.Id, false, false)
+	}
+
+	return
+}
+
+// findRegistry
+// 이미지의 레지스트리 주소와 같은 인증 정보를 찾는다, 없으면 빈 목록 (익명 접근)
+func findRegistry(
+	ref *dkReference.Reference,
+	registries []Registry,
+) []Registry {
+	for _, registry := range registries {
+		if dkReference.NormalizeDomain(registry.GetServerAddress()) == ref.Domain {
+			return []Registry{registry}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,309 @@
+package dkEngine
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	xRegistryConfigHeader         = "X-Registry-Config"
+	httpHeaderValueApplicationTar = "application/x-tar"
+	defaultDockerfile             = "Dockerfile"
+	dockerIgnoreFile              = ".dockerignore"
+)
+
+type BuildOptions struct {
+	// Dockerfile 빌드 컨텍스트 안의 경로, 기본값 Dockerfile
+	Dockerfile string
+	// Tags ex) docker.d3v-friends.com/api:latest
+	Tags      []string
+	BuildArgs map[string]string
+	// Target 멀티 스테이지 빌드의 대상 스테이지
+	Target   string
+	Labels   map[string]string
+	Platform Platform
+	NoCache  bool
+	// Pull 베이스 이미지를 항상 새로 받는다
+	Pull bool
+	// OnProgress 빌드 출력 (Stream) 과 진행 상황을 전달받는다
+	OnProgress ProgressFunc
+	// Registries 베이스 이미지를 받을 때 사용할 인증 정보
+	Registries []Registry
+}
+
+func (x *BuildOptions) query() (res string, err error) {
+	var values = url.Values{}
+	values.Set("dockerfile", x.dockerfile())
+	values.Set("rm", "true")
+	values.Set("nocache", strconv.FormatBool(x.NoCache))
+	values.Set("pull", strconv.FormatBool(x.Pull))
+
+	for _, tag := range x.Tags {
+		values.Add("t", tag)
+	}
+
+	if x.Target != "" {
+		values.Set("target", x.Target)
+	}
+
+	if x.Platform != "" {
+		values.Set("platform", x.Platform.String())
+	}
+
+	if len(x.BuildArgs) != 0 {
+		var body []byte
+		if body, err = json.Marshal(x.BuildArgs); err != nil {
+			return
+		}
+		values.Set("buildargs", string(body))
+	}
+
+	if len(x.Labels) != 0 {
+		var body []byte
+		if body, err = json.Marshal(x.Labels); err != nil {
+			return
+		}
+		values.Set("labels", string(body))
+	}
+
+	res = values.Encode()
+	return
+}
+
+func (x *BuildOptions) dockerfile() string {
+	if x.Dockerfile == "" {
+		return defaultDockerfile
+	}
+	return filepath.ToSlash(x.Dockerfile)
+}
+
+// registryConfig
+// X-Registry-Config 헤더 값, 레지스트리 주소별 인증 정보
+func (x *BuildOptions) registryConfig() (res string, err error) {
+	var config = make(map[string]map[string]string)
+	for _, registry := range x.Registries {
+		config[registry.GetServerAddress()] = map[string]string{
+			"username": registry.GetUsername(),
+			"password": registry.GetPassword(),
+			"email":    registry.GetEmail(),
+		}
+	}
+
+	var body []byte
+	if body, err = json.Marshal(config); err != nil {
+		return
+	}
+
+	res = base64.URLEncoding.EncodeToString(body)
+	return
+}
+
+type BuildResult struct {
+	ID string `json:"ID"`
+}
+
+// Build
+// contextDir 을 .dockerignore 에 따라 tar 로 묶어 /build 로 보내고 만들어진 이미지 id 를 반환한다
+// 스트림 중간에 빌드 에러가 오면 에러를 반환한다
+// 빌드 시간에 제한이 없으므로 ctx 로 취소하거나 제한 시간을 둔다
+func Build(
+	ctx context.Context,
+	host string,
+	contextDir string,
+	opts *BuildOptions,
+) (id string, err error) {
+	if opts == nil {
+		opts = &BuildOptions{}
+	}
+
+	var query string
+	if query, err = opts.query(); err != nil {
+		return
+	}
+
+	var registryConfig string
+	if registryConfig, err = opts.registryConfig(); err != nil {
+		return
+	}
+
+	var ignore *dockerIgnore
+	if ignore, err = loadDockerIgnore(contextDir); err != nil {
+		return
+	}
+
+	var reader, writer = io.Pipe()
+	go func() {
+		_ = writer.CloseWithError(writeBuildContext(writer, contextDir, opts.dockerfile(), ignore))
+	}()
+
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/build?%s", host, query),
+		reader,
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationTar)
+	request.Header.Set(xRegistryConfigHeader, registryConfig)
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case 200:
+		if err = readJsonMessages(resp.Body, opts.OnProgress, func(aux json.RawMessage) (err error) {
+			var result = &BuildResult{}
+			if err = json.Unmarshal(aux, result); err != nil {
+				return
+			}
+
+			if result.ID != "" {
+				id = result.ID
+			}
+			return
+		}); err != nil {
+			return
+		}
+
+		if id == "" {
+			err = fnError.NewFields(ErrStreamMessage, map[string]any{
+				"message": "build finished without image id",
+			})
+			return
+		}
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+func loadDockerIgnore(contextDir string) (res *dockerIgnore, err error) {
+	var file *os.File
+	if file, err = os.Open(filepath.Join(contextDir, dockerIgnoreFile)); err != nil {
+		if os.IsNotExist(err) {
+			res = &dockerIgnore{}
+			err = nil
+		}
+		return
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return parseDockerIgnore(file)
+}
+
+// writeBuildContext
+// Dockerfile 과 .dockerignore 는 제외 패턴과 관계없이 항상 포함한다
+func writeBuildContext(
+	writer io.Writer,
+	contextDir string,
+	dockerfile string,
+	ignore *dockerIgnore,
+) (err error) {
+	var tw = tar.NewWriter(writer)
+
+	if err = filepath.WalkDir(contextDir, func(fp string, entry fs.DirEntry, walkErr error) (err error) {
+		if walkErr != nil {
+			return walkErr
+		}
+
+		var rel string
+		if rel, err = filepath.Rel(contextDir, fp); err != nil {
+			return
+		}
+
+		if rel == "." {
+			return
+		}
+
+		rel = filepath.ToSlash(rel)
+
+		if rel != dockerfile && rel != dockerIgnoreFile && ignore.matches(rel) {
+			// 예외 패턴이 없으면 하위 파일도 모두 제외되므로 디렉토리를 건너뛴다
+			if entry.IsDir() && !ignore.hasException {
+				return filepath.SkipDir
+			}
+			return
+		}
+
+		var info fs.FileInfo
+		if info, err = entry.Info(); err != nil {
+			return
+		}
+
+		var link = ""
+		if info.Mode()&fs.ModeSymlink != 0 {
+			if link, err = os.Readlink(fp); err != nil {
+				return
+			}
+		}
+
+		var header *tar.Header
+		if header, err = tar.FileInfoHeader(info, link); err != nil {
+			return
+		}
+
+		header.Name = rel
+		if entry.IsDir() {
+			header.Name += "/"
+		}
+
+		// 빌드 캐시가 호스트 사용자 정보에 영향받지 않도록 한다
+		header.Uid, header.Gid = 0, 0
+		header.Uname, header.Gname = "", ""
+
+		if err = tw.WriteHeader(header); err != nil {
+			return
+		}
+
+		if !info.Mode().IsRegular() {
+			return
+		}
+
+		var file *os.File
+		if file, err = os.Open(fp); err != nil {
+			return
+		}
+
+		defer func() {
+			_ = file.Close()
+		}()
+
+		_, err = io.Copy(tw, file)
+		return
+	}); err != nil {
+		return
+	}
+
+	return tw.Close()
+}
@@ -0,0 +1,105 @@
+package dkEngine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type CommitOptions struct {
+	Author  string
+	Message string
+	// Pause 커밋하는 동안 컨테이너를 일시 정지한다, 기본값 true
+	Pause *bool
+	// Changes 이미지에 적용할 Dockerfile 명령어 ex) "ENV DEBUG=true", "CMD [\"sh\"]"
+	Changes []string
+	// Registry 있으면 커밋 후 repo:tag 로 push 한다
+	Registry Registry
+}
+
+type CommitResult struct {
+	Id string `json:"id"`
+	// Digest Registry 로 push 한 경우 레지스트리의 digest
+	Digest string `json:"digest,omitempty"`
+}
+
+// Commit
+// 컨테이너의 현재 파일시스템을 repo:tag 이미지로 만든다, 볼륨에 마운트된 데이터는 포함되지 않는다
+// ctx 는 커밋과 push 에 모두 적용된다
+func Commit(
+	ctx context.Context,
+	host string,
+	id string,
+	repo string,
+	tag string,
+	opts *CommitOptions,
+) (res *CommitResult, err error) {
+	opts = fnPointer.Default(opts, CommitOptions{})
+
+	var values = url.Values{}
+	values.Set("container", id)
+	values.Set("repo", repo)
+	values.Set("tag", tag)
+	values.Set("author", opts.Author)
+	values.Set("comment", opts.Message)
+	values.Set("pause", strconv.FormatBool(*fnPointer.Default(opts.Pause, true)))
+	for _, change := range opts.Changes {
+		values.Add("changes", change)
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/commit?%s", host, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationJson)
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Minute * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 201:
+		var result = &CreateContainerResponse{}
+		if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return
+		}
+		res = &CommitResult{
+			Id: result.Id,
+		}
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+
+	if opts.Registry == nil {
+		return
+	}
+
+	var image = repo
+	if tag != "" {
+		image = fmt.Sprintf("%s:%s", repo, tag)
+	}
+
+	if res.Digest, err = Push(ctx, host, image, opts.Registry); err != nil {
+		return
+	}
+
+	return
+}
@@ -2,13 +2,19 @@ package dkEngine
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"github.com/d3v-friends/go-docker/dkReference"
 	"github.com/d3v-friends/go-tools/fnError"
 	"github.com/d3v-friends/go-tools/fnPanic"
 	"github.com/d3v-friends/go-tools/fnPointer"
 	"io"
 	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -31,8 +37,9 @@ func NewCreateContainerArgs(
 			Hostname: nil,
 			User:     nil,
 			Env:      make([]string, 0),
+			Labels:   map[string]string{},
 			Image:    fnPointer.Make(image),
-			Volumes:  map[string]string{},
+			Volumes:  map[string]struct{}{},
 			HostConfig: &HostConfig{
 				LogConfig: &LogConfig{
 					Type: fnPointer.Make("json-file"),
@@ -66,6 +73,137 @@ func NewCreateContainerArgs(
 	}
 }
 
+// NewCreateContainerArgsFromInspection
+// 실행중인 컨테이너와 같은 설정으로 다시 생성하기 위한 인자를 만든다
+// image 는 컨테이너를 생성한 이미지이며 이미지의 기본값 (env, label, cmd 등) 과 같은 값은 제외하여
+// 새 이미지의 기본값이 적용되도록 한다, nil 이면 모든 값을 그대로 사용한다
+// 도커가 자동으로 할당하는 hostname, mac address, 짧은 id alias 는 제외한다
+func NewCreateContainerArgsFromInspection(
+	inspection *ContainerInspection,
+	image *ImageInspection,
+) *CreateContainerArgs {
+	var config = fnPointer.Default(inspection.Config, ContainerInspectionConfig{})
+	var imageConfig = &ImageInspectionConfig{}
+	var platform Platform
+	if image != nil {
+		imageConfig = fnPointer.Default(image.Config, ImageInspectionConfig{})
+		platform = Platform(fmt.Sprintf("%s/%s", image.Os, image.Architecture))
+		if image.Variant != "" {
+			platform = Platform(fmt.Sprintf("%s/%s", platform, image.Variant))
+		}
+	}
+
+	var shortId = inspection.Id
+	if len(shortId) > 12 {
+		shortId = shortId[:12]
+	}
+
+	var args = &CreateContainerRequest{
+		Env:          make([]string, 0),
+		Labels:       map[string]string{},
+		Image:        fnPointer.Make(config.Image),
+		StopTimeout:  config.StopTimeout,
+		Volumes:      map[string]struct{}{},
+		AttachStdin:  config.AttachStdin,
+		AttachStdout: config.AttachStdout,
+		AttachStderr: config.AttachStderr,
+		Tty:          config.Tty,
+		OpenStdin:    config.OpenStdin,
+		StdinOnce:    config.StdinOnce,
+		HostConfig:   inspection.HostConfig,
+		ExposedPorts: ExposedPorts{},
+		NetworkingConfig: &NetworkingConfig{
+			EndpointsConfig: EndpointsConfig{},
+		},
+	}
+
+	// entrypoint 를 바꾸면 이미지의 cmd 는 적용되지 않으므로 함께 지정한다
+	if !slices.Equal(config.Entrypoint, imageConfig.Entrypoint) {
+		args.Entrypoint = config.Entrypoint
+		args.Cmd = config.Cmd
+	} else if !slices.Equal(config.Cmd, imageConfig.Cmd) {
+		args.Cmd = config.Cmd
+	}
+
+	if config.Hostname != "" && config.Hostname != shortId {
+		args.Hostname = fnPointer.Make(config.Hostname)
+	}
+
+	if config.Domainname != "" {
+		args.Domainname = fnPointer.Make(config.Domainname)
+	}
+
+	if config.User != imageConfig.User {
+		args.User = fnPointer.Make(config.User)
+	}
+
+	if config.WorkingDir != imageConfig.WorkingDir {
+		args.WorkingDir = fnPointer.Make(config.WorkingDir)
+	}
+
+	if config.StopSignal != imageConfig.StopSignal {
+		args.StopSignal = fnPointer.Make(config.StopSignal)
+	}
+
+	for _, env := range config.Env {
+		if !slices.Contains(imageConfig.Env, env) {
+			args.Env = append(args.Env, env)
+		}
+	}
+
+	for key, value := range config.Labels {
+		if v, has := imageConfig.Labels[key]; !has || v != value {
+			args.Labels[key] = value
+		}
+	}
+
+	// run 에서 지정한 healthcheck 가 빠지면 Deploy 가 준비 여부를 확인할 수 없다
+	if !fnPointer.IsNil(config.Healthcheck) && !reflect.DeepEqual(config.Healthcheck, imageConfig.Healthcheck) {
+		args.Healthcheck = config.Healthcheck
+	}
+
+	for volume := range config.Volumes {
+		if _, has := imageConfig.Volumes[volume]; !has {
+			args.Volumes[volume] = struct{}{}
+		}
+	}
+
+	for port, value := range config.ExposedPorts {
+		if _, has := imageConfig.ExposedPorts[port]; !has {
+			args.ExposedPorts[port] = value
+		}
+	}
+
+	var networkMode = ""
+	if !fnPointer.IsNil(inspection.HostConfig) {
+		networkMode = *fnPointer.Default(inspection.HostConfig.NetworkMode, "")
+	}
+
+	// host, none, container:<id> 모드는 네트워크를 연결, 분리할 수 없다
+	if !fnPointer.IsNil(inspection.NetworkSettings) && !strings.HasPrefix(networkMode, "container:") {
+		for networkName, endpoint := range inspection.NetworkSettings.Networks {
+			if networkName == "host" || networkName == "none" || fnPointer.IsNil(endpoint) {
+				continue
+			}
+
+			args.NetworkingConfig.EndpointsConfig[networkName] = &EndpointSettings{
+				IPAMConfig: endpoint.IPAMConfig,
+				Links:      endpoint.Links,
+				Aliases:    slices.DeleteFunc(slices.Clone(endpoint.Aliases), func(alias string) bool { return alias == shortId }),
+				DriverOpts: endpoint.DriverOpts,
+				DNSNames:   slices.DeleteFunc(slices.Clone(endpoint.DNSNames), func(name string) bool { return name == shortId }),
+			}
+		}
+	}
+
+	return &CreateContainerArgs{
+		Args:          args,
+		platform:      platform,
+		containerName: strings.TrimPrefix(inspection.Name, "/"),
+		networkName:   networkMode,
+	}
+}
+
 func (x *CreateContainerArgs) Body() ([]byte, error) {
 	return json.Marshal(x.Args)
 }
@@ -82,30 +220,261 @@ func (x *CreateContainerArgs) AppendVolumeBinds(
 	x.Args.HostConfig.Binds = append(x.Args.HostConfig.Binds, args)
 }
 
-func (x *CreateContainerArgs) AppendEnv(key, value string) {
-	x.Args.Env = append(x.Args.Env, fmt.Sprintf("%s=%s", key, value))
-}
-
+// AppendPortBind
+// 모든 인터페이스의 호스트 포트를 컨테이너의 tcp 포트에 바인딩한다
 func (x *CreateContainerArgs) AppendPortBind(
 	host uint64,
 	container uint64,
 ) {
-	var hostPort = fnPointer.Make(fmt.Sprintf("%d", host))
-	x.Args.HostConfig.PortBindings[fmt.Sprintf("%d/tcp", container)] = []*PortBinding{
-		{HostIp: fnPointer.Make("0.0.0.0"), HostPort: hostPort},
-		{HostIp: fnPointer.Make("::"), HostPort: hostPort},
+	x.AppendPortBindSpec(&PortBindSpec{
+		HostPort:      fmt.Sprintf("%d", host),
+		ContainerPort: container,
+		Protocol:      PortProtocolTcp,
+	})
+}
+
+// AppendPortBindWith
+// hostIp 가 비어있으면 모든 인터페이스에 바인딩한다
+// hostPort 가 비어있으면 임의의 포트가 할당되고, "8000-8010" 처럼 범위를 지정할 수 있다
+func (x *CreateContainerArgs) AppendPortBindWith(
+	protocol PortProtocol,
+	hostIp string,
+	hostPort string,
+	container uint64,
+) {
+	x.AppendPortBindSpec(&PortBindSpec{
+		HostIp:        hostIp,
+		HostPort:      hostPort,
+		ContainerPort: container,
+		Protocol:      protocol,
+	})
+}
+
+func (x *CreateContainerArgs) AppendPortBindSpec(specs ...*PortBindSpec) {
+	for _, spec := range specs {
+		var binding = &PortBinding{}
+		if spec.HostIp != "" {
+			binding.HostIp = fnPointer.Make(spec.HostIp)
+		}
+
+		if spec.HostPort != "" {
+			binding.HostPort = fnPointer.Make(spec.HostPort)
+		}
+
+		var key = spec.Key()
+		x.Args.HostConfig.PortBindings[key] = append(x.Args.HostConfig.PortBindings[key], binding)
+		x.Args.ExposedPorts[key] = map[string]string{}
+	}
+}
+
+// AppendPortBindRaw
+// docker cli 의 -p 옵션 형식으로 바인딩을 추가한다 (ParsePortBindSpec 참고)
+func (x *CreateContainerArgs) AppendPortBindRaw(raw string) (err error) {
+	var specs []*PortBindSpec
+	if specs, err = ParsePortBindSpec(raw); err != nil {
+		return
 	}
-	x.Args.ExposedPorts[fmt.Sprintf("%d/tcp", host)] = map[string]string{}
+	x.AppendPortBindSpec(specs...)
+	return
+}
+
+// AppendExposedPort
+// 호스트에 바인딩하지 않고 컨테이너 포트만 노출한다
+func (x *CreateContainerArgs) AppendExposedPort(
+	container uint64,
+	protocol PortProtocol,
+) {
+	x.Args.ExposedPorts[portKey(container, protocol)] = map[string]string{}
+}
+
+// AppendNetwork
+// 컨테이너 생성시 추가로 연결할 네트워크, 여러 네트워크 연결은 엔진 api 1.44 이상에서 지원된다
+func (x *CreateContainerArgs) AppendNetwork(
+	networkName string,
+	aliases ...string,
+) {
+	var endpoint = x.endpoint(networkName)
+	endpoint.Aliases = append(endpoint.Aliases, aliases...)
+	endpoint.DNSNames = append(endpoint.DNSNames, aliases...)
+}
+
+// SetNetworkIPv4
+// 네트워크에 고정 ipv4 주소를 지정한다, 네트워크 생성시 subnet 이 지정되어 있어야 한다
+func (x *CreateContainerArgs) SetNetworkIPv4(
+	networkName string,
+	ip string,
+) {
+	var endpoint = x.endpoint(networkName)
+	if fnPointer.IsNil(endpoint.IPAMConfig) {
+		endpoint.IPAMConfig = &EndpointIPAMConfig{}
+	}
+	endpoint.IPAMConfig.IPv4Address = ip
+}
+
+// SetNetworkIPv6
+// 네트워크에 고정 ipv6 주소를 지정한다, 네트워크 생성시 ipv6 subnet 이 지정되어 있어야 한다
+func (x *CreateContainerArgs) SetNetworkIPv6(
+	networkName string,
+	ip string,
+) {
+	var endpoint = x.endpoint(networkName)
+	if fnPointer.IsNil(endpoint.IPAMConfig) {
+		endpoint.IPAMConfig = &EndpointIPAMConfig{}
+	}
+	endpoint.IPAMConfig.IPv6Address = ip
+}
+
+func (x *CreateContainerArgs) endpoint(networkName string) *EndpointSettings {
+	if fnPointer.IsNil(x.Args.NetworkingConfig) {
+		x.Args.NetworkingConfig = &NetworkingConfig{}
+	}
+
+	if x.Args.NetworkingConfig.EndpointsConfig == nil {
+		x.Args.NetworkingConfig.EndpointsConfig = EndpointsConfig{}
+	}
+
+	var endpoint, has = x.Args.NetworkingConfig.EndpointsConfig[networkName]
+	if !has || fnPointer.IsNil(endpoint) {
+		endpoint = &EndpointSettings{}
+		x.Args.NetworkingConfig.EndpointsConfig[networkName] = endpoint
+	}
+
+	return endpoint
 }
 
 func (x *CreateContainerArgs) SetCmd(cmd []string) {
 	x.Args.Cmd = cmd
 }
 
+func (x *CreateContainerArgs) SetEntrypoint(entrypoint []string) {
+	x.Args.Entrypoint = entrypoint
+}
+
+func (x *CreateContainerArgs) SetHostname(hostname string) {
+	x.Args.Hostname = fnPointer.Make(hostname)
+}
+
+func (x *CreateContainerArgs) SetDomainname(domainname string) {
+	x.Args.Domainname = fnPointer.Make(domainname)
+}
+
+// SetUser
+// "user", "user:group", "uid:gid" 형식
+func (x *CreateContainerArgs) SetUser(user string) {
+	x.Args.User = fnPointer.Make(user)
+}
+
+func (x *CreateContainerArgs) SetWorkingDir(dir string) {
+	x.Args.WorkingDir = fnPointer.Make(dir)
+}
+
+func (x *CreateContainerArgs) AppendLabel(key, value string) {
+	x.Args.Labels[key] = value
+}
+
+func (x *CreateContainerArgs) AppendLabels(labels map[string]string) {
+	for key, value := range labels {
+		x.Args.Labels[key] = value
+	}
+}
+
+// SetStopSignal
+// 컨테이너를 정지할 때 보내는 시그널 ex) SIGTERM, SIGINT, SIGQUIT
+func (x *CreateContainerArgs) SetStopSignal(signal string) {
+	x.Args.StopSignal = fnPointer.Make(signal)
+}
+
+// SetStopTimeout
+// 정지 시그널을 보낸 뒤 강제 종료하기까지 기다리는 시간
+func (x *CreateContainerArgs) SetStopTimeout(timeout time.Duration) {
+	x.Args.StopTimeout = fnPointer.Make(int(timeout / time.Second))
+}
+
 func (x *CreateContainerArgs) SetPrivileged(v bool) {
 	x.Args.HostConfig.Privileged = fnPointer.Make(v)
 }
 
+// AppendCapAdd
+// ex) NET_ADMIN, SYS_PTRACE
+func (x *CreateContainerArgs) AppendCapAdd(caps ...string) {
+	x.Args.HostConfig.CapAdd = append(x.Args.HostConfig.CapAdd, caps...)
+}
+
+// AppendCapDrop
+// "ALL" 을 제거한 뒤 AppendCapAdd 로 필요한 권한만 추가하는 것을 권장한다
+func (x *CreateContainerArgs) AppendCapDrop(caps ...string) {
+	x.Args.HostConfig.CapDrop = append(x.Args.HostConfig.CapDrop, caps...)
+}
+
+// AppendDevice
+// permissions 는 r, w, m 의 조합이며 비어있으면 "rwm"
+func (x *CreateContainerArgs) AppendDevice(
+	host string,
+	container string,
+	permissions string,
+) {
+	if container == "" {
+		container = host
+	}
+
+	if permissions == "" {
+		permissions = "rwm"
+	}
+
+	x.Args.HostConfig.Devices = append(x.Args.HostConfig.Devices, &DeviceMapping{
+		PathOnHost:        host,
+		PathInContainer:   container,
+		CgroupPermissions: permissions,
+	})
+}
+
+// AppendDeviceCgroupRule
+// ex) "c 1:3 mr", "a 7:* rmw"
+func (x *CreateContainerArgs) AppendDeviceCgroupRule(rules ...string) {
+	x.Args.HostConfig.DeviceCgroupRules = append(x.Args.HostConfig.DeviceCgroupRules, rules...)
+}
+
+func (x *CreateContainerArgs) AppendSysctl(key, value string) {
+	if x.Args.HostConfig.Sysctls == nil {
+		x.Args.HostConfig.Sysctls = map[string]string{}
+	}
+	x.Args.HostConfig.Sysctls[key] = value
+}
+
+func (x *CreateContainerArgs) AppendSecurityOpt(opts ...string) {
+	x.Args.HostConfig.SecurityOpt = append(x.Args.HostConfig.SecurityOpt, opts...)
+}
+
+// SetSeccompProfile
+// profile 은 seccomp 프로파일 json 내용 또는 "unconfined"
+func (x *CreateContainerArgs) SetSeccompProfile(profile string) {
+	x.AppendSecurityOpt(fmt.Sprintf("seccomp=%s", profile))
+}
+
+func (x *CreateContainerArgs) SetApparmorProfile(profile string) {
+	x.AppendSecurityOpt(fmt.Sprintf("apparmor=%s", profile))
+}
+
+func (x *CreateContainerArgs) SetNoNewPrivileges() {
+	x.AppendSecurityOpt("no-new-privileges:true")
+}
+
+func (x *CreateContainerArgs) SetReadonlyRootfs(v bool) {
+	x.Args.HostConfig.ReadonlyRootfs = fnPointer.Make(v)
+}
+
+// AppendGroupAdd
+// 컨테이너 프로세스에 추가할 그룹 이름 또는 gid
+func (x *CreateContainerArgs) AppendGroupAdd(groups ...string) {
+	x.Args.HostConfig.GroupAdd = append(x.Args.HostConfig.GroupAdd, groups...)
+}
+
+// SetUsernsMode
+// 데몬에 userns-remap 이 설정된 경우 "host" 로 비활성화 할 수 있다
+func (x *CreateContainerArgs) SetUsernsMode(mode string) {
+	x.Args.HostConfig.UsernsMode = fnPointer.Make(mode)
+}
+
 func (x *CreateContainerArgs) SetNetworkMode(mode string) {
 	x.Args.HostConfig.NetworkMode = fnPointer.Make(mode)
 }
@@ -166,6 +535,12 @@ func CreateContainer(
 
 		id = result.Id
 		return
+	case 409:
+		err = fnError.NewFields(ErrAlreadyHasSameContainerName, map[string]any{
+			"name":    args.containerName,
+			"message": string(fnPanic.Value(io.ReadAll(resp.Body))),
+		})
+		return
 	default:
 		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
 		return
@@ -252,13 +627,33 @@ func Pull(
 	image string,
 	registries ...Registry,
 ) (err error) {
+	return PullWithProgress(context.Background(), host, image, nil, registries...)
+}
+
+// PullWithProgress
+// 스트림으로 전달되는 진행 상황을 onProgress 로 전달한다
+// 스트림 중간에 에러 메시지 (manifest unknown, 인증 실패 등) 가 오면 에러를 반환한다
+// ctx 가 종료되면 pull 을 중단한다
+func PullWithProgress(
+	ctx context.Context,
+	host string,
+	image string,
+	onProgress ProgressFunc,
+	registries ...Registry,
+) (err error) {
+	var ref *dkReference.Reference
+	if ref, err = dkReference.Parse(image); err != nil {
+		return
+	}
+
 	var request *http.Request
-	if request, err = http.NewRequest(
+	if request, err = http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		fmt.Sprintf(
-			"%s/images/create?fromImage=%s",
+			"%s/images/create?%s",
 			host,
-			image,
+			ref.PullQuery().Encode(),
 		),
 		nil,
 	); err != nil {
@@ -277,18 +672,19 @@ func Pull(
 
 	var resp *http.Response
 	if resp, err = (&http.Client{
-		Timeout: time.Second * 60,
+		Timeout: time.Minute * 30,
 	}).Do(request); err != nil {
 		return
 	}
 
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
 	switch resp.StatusCode {
 	case 200, 201, 204:
 		// 결과가 스트림으로 보내지므로 연결을 지속해야 작업이 이뤄진다
-		if _, err = io.ReadAll(resp.Body); err != nil {
-			return
-		}
-		return
+		return readJsonMessages(resp.Body, onProgress, nil)
 	default:
 		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
 		return
@@ -316,7 +712,7 @@ func Stop(
 
 	var resp *http.Response
 	if resp, err = (&http.Client{
-		Timeout: time.Second * 10,
+		Timeout: time.Second * 60,
 	}).Do(request); err != nil {
 		return
 	}
@@ -403,12 +799,64 @@ func Remove(
 
 /* ------------------------------------------------------------------------------------------------------------ */
 
+func Rename(
+	host string,
+	id string,
+	name string,
+) (err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf(
+			"%s/containers/%s/rename?name=%s",
+			host,
+			id,
+			url.QueryEscape(name),
+		),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200, 204:
+		return
+	case 409:
+		err = fnError.NewFields(ErrAlreadyHasSameContainerName, map[string]any{
+			"name":    name,
+			"message": string(fnPanic.Value(io.ReadAll(resp.Body))),
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
 func Inspect(
 	host string,
 	id string,
+) (res *ContainerInspection, err error) {
+	return inspect(context.Background(), host, id)
+}
+
+func inspect(
+	ctx context.Context,
+	host string,
+	id string,
 ) (res *ContainerInspection, err error) {
 	var request *http.Request
-	if request, err = http.NewRequest(
+	if request, err = http.NewRequestWithContext(
+		ctx,
 		http.MethodGet,
 		fmt.Sprintf(
 			"%s/containers/%s/json",
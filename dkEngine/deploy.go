@@ -0,0 +1,319 @@
+package dkEngine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"strconv"
+	"time"
+)
+
+type DeployOptions struct {
+	// Timeout
+	// 새 컨테이너가 준비될 때까지 기다리는 시간, 기본값 60초
+	Timeout time.Duration
+	// Wait
+	// 새 컨테이너의 준비 여부를 확인하는 방법, 기본값은 WaitForHealthy
+	Wait WaitStrategy
+	// AllowDowntime
+	// 호스트 포트가 고정되어 두 컨테이너를 동시에 실행할 수 없을 때 기존 컨테이너를 먼저 정지하는 것을 허용한다
+	// false 이면 ErrDeployRequiresDowntime 을 반환한다
+	AllowDowntime bool
+	Registries    []Registry
+}
+
+type DeployResult struct {
+	Id         string `json:"id"`
+	PreviousId string `json:"previousId"`
+	// Name 새 컨테이너의 현재 이름, 이름 변경에 실패하면 임시 이름이 남는다
+	Name string `json:"name"`
+	// Downtime 호스트 포트가 고정되어 기존 컨테이너를 먼저 정지한 경우
+	Downtime bool `json:"downtime"`
+	// CleanupError 새 컨테이너로 교체한 후 기존 컨테이너의 정지, 삭제에 실패한 경우
+	CleanupError string `json:"cleanupError,omitempty"`
+}
+
+// Deploy
+// 서비스 중단 없이 컨테이너를 교체한다
+// 1. 이미지를 pull 한다
+// 2. 임시 이름으로 새 컨테이너를 생성하여 실행한다 (네트워크 alias, 고정 ip 는 제외)
+// 3. 준비될 때까지 기다린다
+// 4. 기존 컨테이너를 네트워크에서 분리하고 새 컨테이너에 alias, 고정 ip 를 연결한다
+// 5. 기존 컨테이너의 이름을 바꾸고 새 컨테이너에 원래 이름을 붙인다
+// 6. 기존 컨테이너를 정지, 삭제한다, 실패하면 CleanupError 에 기록한다
+// 4 단계까지 실패하거나 ctx 가 종료되면 새 컨테이너를 삭제하고 기존 컨테이너를 원래대로 되돌린다
+// 호스트 포트가 고정된 경우 두 컨테이너가 동시에 실행될 수 없으므로 AllowDowntime 이면 2 단계 전에 기존 컨테이너를 정지한다
+func Deploy(
+	ctx context.Context,
+	host string,
+	args *CreateContainerArgs,
+	opts *DeployOptions,
+) (res *DeployResult, err error) {
+	opts = fnPointer.Default(opts, DeployOptions{})
+	var timeout = opts.Timeout
+	if timeout == 0 {
+		timeout = time.Second * 60
+	}
+
+	var wait = opts.Wait
+	if wait == nil {
+		wait = WaitForHealthy()
+	}
+
+	if err = PullWithProgress(ctx, host, *fnPointer.Default(args.Args.Image, ""), nil, opts.Registries...); err != nil {
+		return
+	}
+
+	var previous *ContainerInspection
+	if previous, err = findContainer(host, args.containerName); err != nil {
+		return
+	}
+
+	var suffix = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	var next *CreateContainerArgs
+	if next, err = args.clone(fmt.Sprintf("%s-next-%s", args.containerName, suffix)); err != nil {
+		return
+	}
+
+	if !fnPointer.IsNil(next.Args.NetworkingConfig) {
+		for _, endpoint := range next.Args.NetworkingConfig.EndpointsConfig {
+			if fnPointer.IsNil(endpoint) {
+				continue
+			}
+			endpoint.Aliases = nil
+			endpoint.DNSNames = nil
+			endpoint.IPAMConfig = nil
+		}
+	}
+
+	res = &DeployResult{
+		Name: next.containerName,
+	}
+
+	var rb = &deployRollback{
+		host:     host,
+		previous: previous,
+	}
+
+	if previous != nil {
+		res.PreviousId = previous.Id
+		if previous.State != nil && previous.State.Running && hasFixedHostPort(args) {
+			if !opts.AllowDowntime {
+				err = fnError.NewFields(ErrDeployRequiresDowntime, map[string]any{
+					"container": args.containerName,
+				})
+				return
+			}
+
+			if err = Stop(host, previous.Id); err != nil {
+				return
+			}
+			rb.stopped = true
+			res.Downtime = true
+		}
+	}
+
+	if err = ctx.Err(); err != nil {
+		err = rb.do(err)
+		return
+	}
+
+	if res.Id, err = CreateContainer(host, next, opts.Registries...); err != nil {
+		err = rb.do(err)
+		return
+	}
+
+	rb.id = res.Id
+
+	if err = Start(host, res.Id); err != nil {
+		err = rb.do(err)
+		return
+	}
+
+	var waitCtx, cancel = context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err = Wait(waitCtx, host, res.Id, wait); err != nil {
+		err = rb.do(err)
+		return
+	}
+
+	var endpoints = EndpointsConfig{}
+	if !fnPointer.IsNil(args.Args.NetworkingConfig) {
+		endpoints = args.Args.NetworkingConfig.EndpointsConfig
+	}
+
+	for networkName, endpoint := range endpoints {
+		if err = ctx.Err(); err != nil {
+			err = rb.do(err)
+			return
+		}
+
+		if previous != nil && previous.connected(networkName) {
+			if err = DisconnectNetwork(host, networkName, previous.Id, true); err != nil {
+				err = rb.do(err)
+				return
+			}
+			rb.disconnected = append(rb.disconnected, networkName)
+		}
+
+		if err = DisconnectNetwork(host, networkName, res.Id, true); err != nil {
+			err = rb.do(err)
+			return
+		}
+
+		if err = ConnectNetwork(host, networkName, res.Id, endpoint); err != nil {
+			err = rb.do(err)
+			return
+		}
+	}
+
+	// 여기부터는 새 컨테이너가 서비스 중이므로 되돌리지 않으며 ctx 가 종료되어도 이름 변경까지 마친다
+	if previous != nil {
+		if err = Rename(host, previous.Id, fmt.Sprintf("%s-prev-%s", args.containerName, suffix)); err != nil {
+			return
+		}
+	}
+
+	if err = Rename(host, res.Id, args.containerName); err != nil {
+		return
+	}
+
+	res.Name = args.containerName
+
+	if previous != nil {
+		var errs = make([]error, 0)
+		if !rb.stopped {
+			if stopErr := Stop(host, previous.Id); stopErr != nil {
+				errs = append(errs, stopErr)
+			}
+		}
+
+		if len(errs) == 0 {
+			if removeErr := Remove(host, previous.Id); removeErr != nil {
+				errs = append(errs, removeErr)
+			}
+		}
+
+		if len(errs) != 0 {
+			res.CleanupError = fnError.Concat(errs...).Error()
+		}
+	}
+
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type deployRollback struct {
+	host         string
+	id           string
+	previous     *ContainerInspection
+	stopped      bool
+	disconnected []string
+}
+
+func (x *deployRollback) do(cause error) error {
+	var errs = []error{cause}
+	if x.id != "" {
+		if err := Kill(x.host, x.id); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := Remove(x.host, x.id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if x.previous != nil {
+		for _, networkName := range x.disconnected {
+			var settings = x.previous.NetworkSettings.Networks[networkName]
+			if err := ConnectNetwork(x.host, networkName, x.previous.Id, &EndpointSettings{
+				IPAMConfig: settings.IPAMConfig,
+				Aliases:    settings.Aliases,
+				DNSNames:   settings.DNSNames,
+			}); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		if x.stopped {
+			if err := Start(x.host, x.previous.Id); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return fnError.NewFields(ErrDeployRolledBack, map[string]any{
+		"error": fnError.Concat(errs...).Error(),
+	})
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+func findContainer(
+	host string,
+	name string,
+) (res *ContainerInspection, err error) {
+	var containers Containers
+	if containers, err = QueryContainers(host); err != nil {
+		return
+	}
+
+	for _, container := range containers {
+		if container.Names.Has(name) {
+			return Inspect(host, container.Id)
+		}
+	}
+
+	return
+}
+
+func hasFixedHostPort(args *CreateContainerArgs) bool {
+	if fnPointer.IsNil(args.Args.HostConfig) {
+		return false
+	}
+
+	for _, bindings := range args.Args.HostConfig.PortBindings {
+		for _, binding := range bindings {
+			if !fnPointer.IsNil(binding) && *fnPointer.Default(binding.HostPort, "") != "" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (x *ContainerInspection) connected(networkName string) bool {
+	if fnPointer.IsNil(x.NetworkSettings) {
+		return false
+	}
+	var _, has = x.NetworkSettings.Networks[networkName]
+	return has
+}
+
+// clone
+// 이름만 다른 CreateContainerArgs 를 만든다
+func (x *CreateContainerArgs) clone(containerName string) (res *CreateContainerArgs, err error) {
+	var body []byte
+	if body, err = x.Body(); err != nil {
+		return
+	}
+
+	res = &CreateContainerArgs{
+		Args:          &CreateContainerRequest{},
+		platform:      x.platform,
+		containerName: containerName,
+		networkName:   x.networkName,
+	}
+
+	if err = json.Unmarshal(body, res.Args); err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,120 @@
+package dkEngine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-docker/dkReference"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"io"
+	"net/http"
+	"time"
+)
+
+type DistributionInspection struct {
+	Descriptor *DistributionDescriptor `json:"Descriptor"`
+	Platforms  []*DistributionPlatform `json:"Platforms"`
+}
+
+// DistributionDescriptor
+// 레지스트리의 manifest (멀티 플랫폼 이미지는 manifest list) 정보
+type DistributionDescriptor struct {
+	MediaType string   `json:"mediaType"`
+	Digest    string   `json:"digest"`
+	Size      int64    `json:"size"`
+	Urls      []string `json:"urls"`
+}
+
+type DistributionPlatform struct {
+	Architecture string   `json:"architecture"`
+	Os           string   `json:"os"`
+	OsVersion    string   `json:"os.version"`
+	OsFeatures   []string `json:"os.features"`
+	Variant      string   `json:"variant"`
+	Features     []string `json:"features"`
+}
+
+// Platform
+// ex) linux/amd64, linux/arm64/v8
+func (x *DistributionPlatform) Platform() Platform {
+	if x.Variant == "" {
+		return Platform(fmt.Sprintf("%s/%s", x.Os, x.Architecture))
+	}
+	return Platform(fmt.Sprintf("%s/%s/%s", x.Os, x.Architecture, x.Variant))
+}
+
+// Digest
+// 레지스트리에 올라간 이미지의 digest, 로컬 이미지의 RepoDigests 와 비교할 수 있다
+func (x *DistributionInspection) Digest() string {
+	if x.Descriptor == nil {
+		return ""
+	}
+	return x.Descriptor.Digest
+}
+
+func (x *DistributionInspection) HasPlatform(platform Platform) bool {
+	for _, p := range x.Platforms {
+		if p.Platform() == platform {
+			return true
+		}
+	}
+	return false
+}
+
+// DistributionInspect
+// 도커 엔진을 통해 레지스트리에 있는 이미지의 digest 와 지원 플랫폼을 조회한다, 이미지를 pull 하지 않는다
+// private 레지스트리는 registries 로 인증 정보를 전달한다
+func DistributionInspect(
+	ctx context.Context,
+	host string,
+	image string,
+	registries ...Registry,
+) (res *DistributionInspection, err error) {
+	var ref *dkReference.Reference
+	if ref, err = dkReference.Parse(image); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/distribution/%s/json", host, ref.String()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	if len(registries) == 1 {
+		var token string
+		if token, err = createRegistryToken(registries[0]); err != nil {
+			return
+		}
+		request.Header.Set(xRegistryAuthHeader, token)
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 30,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		res = &DistributionInspection{}
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundImage, map[string]any{
+			"image": image,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
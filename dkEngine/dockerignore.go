@@ -0,0 +1,150 @@
+package dkEngine
+
+import (
+	"bufio"
+	"github.com/d3v-friends/go-tools/fnError"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// dockerIgnore
+// .dockerignore 의 패턴 목록, 뒤에 나오는 패턴이 우선하며 ! 로 시작하면 예외 패턴이다
+// https://docs.docker.com/build/concepts/context/#dockerignore-files
+type dockerIgnore struct {
+	patterns     []*dockerIgnorePattern
+	hasException bool
+}
+
+type dockerIgnorePattern struct {
+	raw       string
+	regexp    *regexp.Regexp
+	exception bool
+}
+
+func parseDockerIgnore(reader io.Reader) (res *dockerIgnore, err error) {
+	res = &dockerIgnore{
+		patterns: make([]*dockerIgnorePattern, 0),
+	}
+
+	var scanner = bufio.NewScanner(reader)
+	for scanner.Scan() {
+		var line = strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var exception = strings.HasPrefix(line, "!")
+		if exception {
+			line = strings.TrimSpace(line[1:])
+			if line == "" {
+				err = fnError.New("illegal exclusion pattern: \"!\"")
+				return
+			}
+		}
+
+		line = filepath.ToSlash(filepath.Clean(line))
+		line = strings.TrimPrefix(line, "/")
+		if line == "" || line == "." {
+			continue
+		}
+
+		var compiled *regexp.Regexp
+		if compiled, err = compileDockerIgnorePattern(line); err != nil {
+			return
+		}
+
+		res.hasException = res.hasException || exception
+		res.patterns = append(res.patterns, &dockerIgnorePattern{
+			raw:       line,
+			regexp:    compiled,
+			exception: exception,
+		})
+	}
+
+	err = scanner.Err()
+	return
+}
+
+// compileDockerIgnorePattern
+// * 는 '/' 를 제외한 문자열, ? 는 '/' 를 제외한 한 문자, ** 는 여러 디렉토리와 일치한다
+func compileDockerIgnorePattern(pattern string) (res *regexp.Regexp, err error) {
+	var sb = strings.Builder{}
+	sb.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		var ch = pattern[i]
+		switch {
+		case ch == '*' && i+1 < len(pattern) && pattern[i+1] == '*':
+			i++
+			if i+1 < len(pattern) && pattern[i+1] == '/' {
+				// "**/" 는 0 개 이상의 디렉토리
+				i++
+				sb.WriteString("(.*/)?")
+			} else {
+				sb.WriteString(".*")
+			}
+		case ch == '*':
+			sb.WriteString("[^/]*")
+		case ch == '?':
+			sb.WriteString("[^/]")
+		case ch == '[':
+			var end = strings.IndexByte(pattern[i:], ']')
+			if end == -1 {
+				err = fnError.NewF("invalid pattern: %s", pattern)
+				return
+			}
+			var class = pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		case ch == '\\' && i+1 < len(pattern):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(pattern[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// matches
+// 파일 또는 상위 디렉토리가 패턴과 일치하면 제외된다
+func (x *dockerIgnore) matches(file string) bool {
+	file = filepath.ToSlash(file)
+
+	var parents = make([]string, 0)
+	for dir := path.Dir(file); dir != "." && dir != "/"; dir = path.Dir(dir) {
+		parents = append(parents, dir)
+	}
+
+	var matched = false
+	for _, pattern := range x.patterns {
+		if pattern.exception != matched {
+			// 결과가 바뀌지 않는 패턴은 건너뛴다
+			continue
+		}
+
+		var match = pattern.regexp.MatchString(file)
+		if !match {
+			for _, parent := range parents {
+				if pattern.regexp.MatchString(parent) {
+					match = true
+					break
+				}
+			}
+		}
+
+		if match {
+			matched = !pattern.exception
+		}
+	}
+
+	return matched
+}
@@ -0,0 +1,66 @@
+package dkEngine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDockerIgnoreMatches(test *testing.T) {
+	var cases = []struct {
+		name     string
+		patterns string
+		file     string
+		want     bool
+	}{
+		{"star", "*.log", "a.log", true},
+		{"star does not cross directory", "*.log", "dir/a.log", false},
+		{"double star prefix", "**/*.log", "dir/sub/a.log", true},
+		{"double star prefix matches root", "**/*.log", "a.log", true},
+		{"double star suffix", "dir/**", "dir/sub/a.go", true},
+		{"double star middle", "a/**/z", "a/b/c/z", true},
+		{"question mark", "temp?", "temp1", true},
+		{"question mark single char", "temp?", "temp12", false},
+		{"character class", "[a-c].txt", "b.txt", true},
+		{"character class miss", "[a-c].txt", "d.txt", false},
+		{"negated character class", "[!a].txt", "a.txt", false},
+		{"escaped", `\*.txt`, "*.txt", true},
+		{"parent directory", "node_modules", "node_modules/pkg/index.js", true},
+		{"leading slash", "/build", "build/out.bin", true},
+		{"leading dot slash", "./vendor", "vendor/lib.go", true},
+		{"comment ignored", "# *.go", "main.go", false},
+		{"exception", "*.md\n!README.md", "README.md", false},
+		{"exception other file", "*.md\n!README.md", "CHANGES.md", true},
+		{"exception inside excluded directory", "docs\n!docs/keep.md", "docs/keep.md", false},
+		{"excluded directory other file", "docs\n!docs/keep.md", "docs/drop.md", true},
+		{"later pattern wins", "!a.txt\na.txt", "a.txt", true},
+		{"no pattern", "", "main.go", false},
+	}
+
+	for _, c := range cases {
+		test.Run(c.name, func(t *testing.T) {
+			var ignore, err = parseDockerIgnore(strings.NewReader(c.patterns))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if got := ignore.matches(c.file); got != c.want {
+				t.Fatalf("patterns %q, file %q: got %t, want %t", c.patterns, c.file, got, c.want)
+			}
+		})
+	}
+}
+
+func TestDockerIgnoreInvalid(test *testing.T) {
+	var cases = []string{
+		"!",
+		"[abc",
+	}
+
+	for _, patterns := range cases {
+		test.Run(patterns, func(t *testing.T) {
+			if _, err := parseDockerIgnore(strings.NewReader(patterns)); err == nil {
+				t.Fatal("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,354 @@
+package dkEngine
+
+import (
+	"context"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type EnsureAction string
+
+const (
+	EnsureActionNone      EnsureAction = "none"
+	EnsureActionStarted   EnsureAction = "started"
+	EnsureActionCreated   EnsureAction = "created"
+	EnsureActionRecreated EnsureAction = "recreated"
+)
+
+func (x EnsureAction) String() string {
+	return string(x)
+}
+
+// Drift
+// 현재 상태와 원하는 설정의 차이 하나
+// 컨테이너: Field 는 image, env.KEY, label.KEY, binds, ports, networks 중 하나
+// 네트워크: Field 는 driver, internal, subnets 중 하나
+type Drift struct {
+	Field   string `json:"field"`
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+type Drifts []*Drift
+
+func (x Drifts) HasDrift() bool {
+	return len(x) != 0
+}
+
+func (x Drifts) String() string {
+	var ls = make([]string, len(x))
+	for i, drift := range x {
+		ls[i] = fmt.Sprintf("%s: %q -> %q", drift.Field, drift.Current, drift.Desired)
+	}
+	return strings.Join(ls, ", ")
+}
+
+type EnsureContainerResult struct {
+	Id     string       `json:"id"`
+	Action EnsureAction `json:"action"`
+	Drifts Drifts       `json:"drifts"`
+}
+
+// EnsureContainer
+// 같은 이름의 컨테이너가 없으면 생성 후 실행하고,
+// 있으면 CreateContainerArgs 와 비교하여 같으면 (정지 상태라면 실행만) 그대로 두고
+// 다르면 정지, 삭제 후 다시 생성하여 실행한다
+// 이미지는 로컬에 있어야 하며 필요하면 먼저 Pull 해야 한다
+// ctx 는 각 단계 (조회, 정지, 삭제, 생성, 실행) 전에 확인하며 종료되면 다음 단계를 진행하지 않는다
+func EnsureContainer(
+	ctx context.Context,
+	host string,
+	args *CreateContainerArgs,
+	registries ...Registry,
+) (res *EnsureContainerResult, err error) {
+	if res, err = ensureContainer(ctx, host, args, registries...); err == nil {
+		return
+	}
+
+	// 다른 프로세스가 같은 이름으로 먼저 생성한 경우 한번 더 비교한다
+	if !isErrorCode(err, ErrAlreadyHasSameContainerName) {
+		return
+	}
+
+	return ensureContainer(ctx, host, args, registries...)
+}
+
+func ensureContainer(
+	ctx context.Context,
+	host string,
+	args *CreateContainerArgs,
+	registries ...Registry,
+) (res *EnsureContainerResult, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	var containers Containers
+	if containers, err = QueryContainers(host); err != nil {
+		return
+	}
+
+	var current *Container
+	for _, container := range containers {
+		if container.Names.Has(args.containerName) {
+			current = container
+			break
+		}
+	}
+
+	res = &EnsureContainerResult{
+		Action: EnsureActionCreated,
+		Drifts: make(Drifts, 0),
+	}
+
+	if current != nil {
+		var inspection *ContainerInspection
+		if inspection, err = inspect(ctx, host, current.Id); err != nil {
+			return
+		}
+
+		if res.Drifts, err = DiffContainer(host, inspection, args); err != nil {
+			return
+		}
+
+		if !res.Drifts.HasDrift() {
+			res.Id = inspection.Id
+			res.Action = EnsureActionNone
+			if !fnPointer.IsNil(inspection.State) && inspection.State.Running {
+				return
+			}
+
+			if err = ctx.Err(); err != nil {
+				return
+			}
+
+			res.Action = EnsureActionStarted
+			err = Start(host, inspection.Id)
+			return
+		}
+
+		if !fnPointer.IsNil(inspection.State) && inspection.State.Running {
+			if err = ctx.Err(); err != nil {
+				return
+			}
+
+			if err = Stop(host, inspection.Id); err != nil {
+				return
+			}
+		}
+
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		if err = Remove(host, inspection.Id); err != nil {
+			return
+		}
+
+		res.Action = EnsureActionRecreated
+	}
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	if res.Id, err = CreateContainer(host, args, registries...); err != nil {
+		return
+	}
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	if err = Start(host, res.Id); err != nil {
+		return
+	}
+
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// DiffContainer
+// 컨테이너 조회 결과와 CreateContainerArgs 를 비교한다
+// 이미지에 포함된 env, label 은 이미지 값과 같으면 차이로 보지 않는다
+func DiffContainer(
+	host string,
+	inspection *ContainerInspection,
+	args *CreateContainerArgs,
+) (ls Drifts, err error) {
+	ls = make(Drifts, 0)
+
+	var config = fnPointer.Default(inspection.Config, ContainerInspectionConfig{})
+	var hostConfig = fnPointer.Default(inspection.HostConfig, HostConfig{})
+	var desiredImage = fnPointer.Default(args.Args.Image, "")
+
+	var image *ImageInspection
+	if image, err = InspectImage(host, *desiredImage); err != nil {
+		return
+	}
+
+	if config.Image != *desiredImage || inspection.Image != image.Id {
+		ls = append(ls, &Drift{
+			Field:   "image",
+			Current: fmt.Sprintf("%s@%s", config.Image, inspection.Image),
+			Desired: fmt.Sprintf("%s@%s", *desiredImage, image.Id),
+		})
+	}
+
+	var imageConfig = fnPointer.Default(image.Config, ImageInspectionConfig{})
+
+	var imageEnv = make(map[string]string)
+	for _, env := range imageConfig.Env {
+		var key, value, _ = strings.Cut(env, "=")
+		imageEnv[key] = value
+	}
+
+	var desiredEnv = make(map[string]string)
+	for _, env := range args.Args.Env {
+		var key, value, _ = strings.Cut(env, "=")
+		desiredEnv[key] = value
+	}
+
+	ls = append(ls, diffMap("env", inspection.Env(), desiredEnv, imageEnv)...)
+	ls = append(ls, diffMap("label", config.Labels, args.Args.Labels, imageConfig.Labels)...)
+
+	if current, desired := sortedCopy(hostConfig.Binds), sortedCopy(args.Args.HostConfig.Binds); !slices.Equal(current, desired) {
+		ls = append(ls, &Drift{
+			Field:   "binds",
+			Current: strings.Join(current, ","),
+			Desired: strings.Join(desired, ","),
+		})
+	}
+
+	if current, desired := portBindingsString(hostConfig.PortBindings), portBindingsString(args.Args.HostConfig.PortBindings); current != desired {
+		ls = append(ls, &Drift{
+			Field:   "ports",
+			Current: current,
+			Desired: desired,
+		})
+	}
+
+	var currentNetworks = make([]string, 0)
+	if !fnPointer.IsNil(inspection.NetworkSettings) {
+		for name := range inspection.NetworkSettings.Networks {
+			currentNetworks = append(currentNetworks, name)
+		}
+	}
+
+	var desiredNetworks = make([]string, 0)
+	if !fnPointer.IsNil(args.Args.NetworkingConfig) {
+		for name := range args.Args.NetworkingConfig.EndpointsConfig {
+			desiredNetworks = append(desiredNetworks, name)
+		}
+	}
+
+	// NetworkMode 의 네트워크에도 연결된다 (host, none, container:<id> 제외)
+	if networkMode, has := namedNetworkMode(*fnPointer.Default(args.Args.HostConfig.NetworkMode, "")); has && !slices.Contains(desiredNetworks, networkMode) {
+		desiredNetworks = append(desiredNetworks, networkMode)
+	}
+
+	if current, desired := sortedCopy(currentNetworks), sortedCopy(desiredNetworks); !slices.Equal(current, desired) {
+		ls = append(ls, &Drift{
+			Field:   "networks",
+			Current: strings.Join(current, ","),
+			Desired: strings.Join(desired, ","),
+		})
+	}
+
+	return
+}
+
+func diffMap(
+	field string,
+	current map[string]string,
+	desired map[string]string,
+	inherited map[string]string,
+) (ls Drifts) {
+	ls = make(Drifts, 0)
+
+	var keys = make([]string, 0, len(current)+len(desired))
+	for key := range current {
+		keys = append(keys, key)
+	}
+
+	for key := range desired {
+		if _, has := current[key]; !has {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		var currentValue, hasCurrent = current[key]
+		var desiredValue, hasDesired = desired[key]
+		if !hasDesired {
+			// 이미지에서 상속받은 값은 차이로 보지 않는다
+			if inheritedValue, has := inherited[key]; has && inheritedValue == currentValue {
+				continue
+			}
+		}
+
+		if hasCurrent == hasDesired && currentValue == desiredValue {
+			continue
+		}
+
+		ls = append(ls, &Drift{
+			Field:   fmt.Sprintf("%s.%s", field, key),
+			Current: currentValue,
+			Desired: desiredValue,
+		})
+	}
+
+	return
+}
+
+func portBindingsString(bindings PortBindings) string {
+	var ls = make([]string, 0)
+	for key, values := range bindings {
+		for _, binding := range values {
+			if fnPointer.IsNil(binding) {
+				continue
+			}
+
+			ls = append(ls, fmt.Sprintf(
+				"%s:%s->%s",
+				*fnPointer.Default(binding.HostIp, ""),
+				*fnPointer.Default(binding.HostPort, ""),
+				key,
+			))
+		}
+	}
+	sort.Strings(ls)
+	return strings.Join(ls, ",")
+}
+
+func sortedCopy(vs []string) (ls []string) {
+	ls = make([]string, len(vs))
+	copy(ls, vs)
+	sort.Strings(ls)
+	return
+}
+
+func isErrorCode(err error, code string) bool {
+	return err != nil && strings.HasPrefix(err.Error(), code)
+}
+
+// namedNetworkMode
+// NetworkMode 가 가리키는 네트워크 이름, "default" 는 bridge 이다
+func namedNetworkMode(networkMode string) (string, bool) {
+	switch {
+	case networkMode == "", networkMode == "host", networkMode == "none":
+		return "", false
+	case strings.HasPrefix(networkMode, "container:"):
+		return "", false
+	case networkMode == "default":
+		return "bridge", true
+	default:
+		return networkMode, true
+	}
+}
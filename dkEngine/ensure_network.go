@@ -0,0 +1,221 @@
+package dkEngine
+
+import (
+	"context"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const defaultNetworkDriver = "bridge"
+
+type EnsureNetworkResult struct {
+	Id     string       `json:"id"`
+	Action EnsureAction `json:"action"`
+	Drifts Drifts       `json:"drifts"`
+	// Duplicates 같은 이름으로 이미 여러개 생성되어 남아있는 네트워크의 id, 가장 먼저 생성된 네트워크를 사용한다
+	Duplicates []string `json:"duplicates,omitempty"`
+}
+
+// EnsureNetwork
+// spec.Name 과 같은 이름 (spec.Labels 가 있으면 라벨까지 일치) 의 네트워크가 없으면 생성한다
+// 있으면 driver, internal, subnet 을 비교하여 차이를 Drifts 로 반환하며
+// recreate 가 true 이고 연결된 컨테이너가 없을 때만 삭제 후 다시 생성한다
+// 다시 생성할 때는 같은 이름의 중복 네트워크도 모두 삭제하며, 중복 네트워크에 연결된 컨테이너가 있으면 다시 생성하지 않는다
+// ctx 는 각 네트워크를 삭제하기 전과 생성하기 전에 확인한다
+func EnsureNetwork(
+	ctx context.Context,
+	host string,
+	spec *CreateNetworkRequest,
+	recreate bool,
+) (res *EnsureNetworkResult, err error) {
+	if res, err = ensureNetwork(ctx, host, spec, recreate); err == nil {
+		return
+	}
+
+	// 다른 프로세스가 같은 이름으로 먼저 생성한 경우 한번 더 비교한다
+	if !isErrorCode(err, ErrAlreadyHasSameNetworkName) {
+		return
+	}
+
+	return ensureNetwork(ctx, host, spec, recreate)
+}
+
+func ensureNetwork(
+	ctx context.Context,
+	host string,
+	spec *CreateNetworkRequest,
+	recreate bool,
+) (res *EnsureNetworkResult, err error) {
+	// 엔진 api 1.44 미만에서는 중복 이름을 허용하므로 확인을 요청한다
+	var args = *spec
+	args.CheckDuplicate = true
+
+	var networks Networks
+	if networks, err = QueryNetworks(host); err != nil {
+		return
+	}
+
+	var candidates = make(Networks, 0)
+	for _, network := range networks {
+		if network.Name != spec.Name || !hasLabels(network.Labels, spec.Labels) {
+			continue
+		}
+		candidates = append(candidates, network)
+	}
+
+	res = &EnsureNetworkResult{
+		Action: EnsureActionCreated,
+		Drifts: make(Drifts, 0),
+	}
+
+	if len(candidates) == 0 {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		var created *CreateNetworkResponse
+		if created, err = CreateNetworkWith(host, &args); err != nil {
+			return
+		}
+		res.Id = created.Id
+		return
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Created.Before(candidates[j].Created)
+	})
+
+	for _, duplicate := range candidates[1:] {
+		res.Duplicates = append(res.Duplicates, duplicate.Id)
+	}
+
+	var current *Network
+	if current, err = InspectNetwork(host, candidates[0].Id); err != nil {
+		return
+	}
+
+	res.Id = current.Id
+	res.Action = EnsureActionNone
+	res.Drifts = DiffNetwork(current, spec)
+
+	if !res.Drifts.HasDrift() || !recreate || len(current.Containers) != 0 {
+		return
+	}
+
+	// 중복 네트워크가 남아있으면 CheckDuplicate 로 다시 생성할 수 없으므로 함께 삭제한다
+	// 컨테이너가 연결된 중복 네트워크가 있으면 다시 생성하지 않는다
+	for _, duplicate := range candidates[1:] {
+		var inspection *Network
+		if inspection, err = InspectNetwork(host, duplicate.Id); err != nil {
+			return
+		}
+
+		if len(inspection.Containers) != 0 {
+			return
+		}
+	}
+
+	for _, duplicate := range candidates[1:] {
+		if err = ctx.Err(); err != nil {
+			return
+		}
+
+		if err = DeleteNetwork(host, duplicate.Id); err != nil {
+			return
+		}
+	}
+
+	res.Duplicates = nil
+
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	if err = DeleteNetwork(host, current.Id); err != nil {
+		return
+	}
+
+	var created *CreateNetworkResponse
+	if created, err = CreateNetworkWith(host, &args); err != nil {
+		return
+	}
+
+	res.Id = created.Id
+	res.Action = EnsureActionRecreated
+	return
+}
+
+// DiffNetwork
+// driver, internal, subnet 을 비교한다, spec 에 IPAM 설정이 없으면 subnet 은 비교하지 않는다
+func DiffNetwork(
+	current *Network,
+	spec *CreateNetworkRequest,
+) (ls Drifts) {
+	ls = make(Drifts, 0)
+
+	var desiredDriver = spec.Driver
+	if desiredDriver == "" {
+		desiredDriver = defaultNetworkDriver
+	}
+
+	if current.Driver != desiredDriver {
+		ls = append(ls, &Drift{
+			Field:   "driver",
+			Current: current.Driver,
+			Desired: desiredDriver,
+		})
+	}
+
+	if current.Internal != spec.Internal {
+		ls = append(ls, &Drift{
+			Field:   "internal",
+			Current: strconv.FormatBool(current.Internal),
+			Desired: strconv.FormatBool(spec.Internal),
+		})
+	}
+
+	if fnPointer.IsNil(spec.IPAM) || len(spec.IPAM.Config) == 0 {
+		return
+	}
+
+	var currentSubnets = subnets(current.IPAM)
+	var desiredSubnets = subnets(spec.IPAM)
+	if !slices.Equal(currentSubnets, desiredSubnets) {
+		ls = append(ls, &Drift{
+			Field:   "subnets",
+			Current: strings.Join(currentSubnets, ","),
+			Desired: strings.Join(desiredSubnets, ","),
+		})
+	}
+
+	return
+}
+
+func subnets(ipam *IPAM) (ls []string) {
+	ls = make([]string, 0)
+	if fnPointer.IsNil(ipam) {
+		return
+	}
+
+	for _, config := range ipam.Config {
+		if fnPointer.IsNil(config) || config.Subnet == "" {
+			continue
+		}
+		ls = append(ls, config.Subnet)
+	}
+
+	sort.Strings(ls)
+	return
+}
+
+func hasLabels(current map[string]string, desired map[string]string) bool {
+	for key, value := range desired {
+		if v, has := current[key]; !has || v != value {
+			return false
+		}
+	}
+	return true
+}
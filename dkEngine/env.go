@@ -0,0 +1,188 @@
+package dkEngine
+
+import (
+	"bufio"
+	"github.com/d3v-friends/go-tools/fnError"
+	"io"
+	"os"
+	"strings"
+)
+
+// ParseEnvFile
+// docker 의 --env-file 형식을 해석하여 "KEY=VALUE" 목록을 순서대로 반환한다
+// - 빈 줄과 # 으로 시작하는 줄은 무시한다
+// - 값은 따옴표를 포함해 그대로 사용한다
+// - "KEY" 처럼 값이 없으면 vars 에서 값을 찾고, 없으면 무시한다
+// - 값 안의 ${VAR}, ${VAR:-default} 는 vars 로 치환한다 (InterpolateEnv 참고)
+func ParseEnvFile(
+	reader io.Reader,
+	vars map[string]string,
+) (ls []string, err error) {
+	ls = make([]string, 0)
+
+	var scanner = bufio.NewScanner(reader)
+	var line = 0
+	for scanner.Scan() {
+		line++
+		var str = strings.TrimLeft(scanner.Text(), " \t")
+		if str == "" || strings.HasPrefix(str, "#") {
+			continue
+		}
+
+		var key, value, hasValue = strings.Cut(str, "=")
+		key = strings.TrimSpace(key)
+		if key == "" || strings.ContainsAny(key, " \t") {
+			err = fnError.NewFields(ErrInvalidEnvFile, map[string]any{
+				"line": line,
+				"text": str,
+			})
+			return
+		}
+
+		if !hasValue {
+			var v, has = vars[key]
+			if !has {
+				continue
+			}
+			ls = append(ls, key+"="+v)
+			continue
+		}
+
+		if value, err = InterpolateEnv(value, vars); err != nil {
+			err = fnError.NewFields(ErrInvalidEnvFile, map[string]any{
+				"line":  line,
+				"text":  str,
+				"error": err.Error(),
+			})
+			return
+		}
+
+		ls = append(ls, key+"="+value)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return
+	}
+
+	return
+}
+
+// InterpolateEnv
+// ${VAR} 는 vars 의 값으로, ${VAR:-default} 는 값이 없거나 비어있으면 default 로 치환한다
+// $$ 는 $ 로 치환된다
+func InterpolateEnv(
+	value string,
+	vars map[string]string,
+) (res string, err error) {
+	var sb = strings.Builder{}
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 >= len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		switch value[i+1] {
+		case '$':
+			sb.WriteByte('$')
+			i++
+		case '{':
+			var end = strings.IndexByte(value[i+2:], '}')
+			if end == -1 {
+				err = fnError.NewF("unterminated variable: %s", value[i:])
+				return
+			}
+
+			var expr = value[i+2 : i+2+end]
+			var name, def, hasDef = strings.Cut(expr, ":-")
+			if name == "" {
+				err = fnError.NewF("empty variable name: %s", value[i:i+3+end])
+				return
+			}
+
+			var v = vars[name]
+			if v == "" && hasDef {
+				v = def
+			}
+
+			sb.WriteString(v)
+			i += 2 + end
+		default:
+			sb.WriteByte(value[i])
+		}
+	}
+
+	res = sb.String()
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// AppendEnv
+// 이미 같은 키가 있으면 값을 덮어쓴다
+func (x *CreateContainerArgs) AppendEnv(key, value string) {
+	var str = key + "=" + value
+	for i, env := range x.Args.Env {
+		if envKey(env) == key {
+			x.Args.Env[i] = str
+			return
+		}
+	}
+	x.Args.Env = append(x.Args.Env, str)
+}
+
+func (x *CreateContainerArgs) AppendEnvMap(m map[string]string) {
+	for key, value := range m {
+		x.AppendEnv(key, value)
+	}
+}
+
+func (x *CreateContainerArgs) RemoveEnv(key string) {
+	var ls = make([]string, 0, len(x.Args.Env))
+	for _, env := range x.Args.Env {
+		if envKey(env) == key {
+			continue
+		}
+		ls = append(ls, env)
+	}
+	x.Args.Env = ls
+}
+
+// LoadEnvReader
+// ParseEnvFile 로 읽은 값을 AppendEnv 로 추가한다, 뒤에 나온 값이 우선한다
+func (x *CreateContainerArgs) LoadEnvReader(
+	reader io.Reader,
+	vars map[string]string,
+) (err error) {
+	var ls []string
+	if ls, err = ParseEnvFile(reader, vars); err != nil {
+		return
+	}
+
+	for _, env := range ls {
+		var key, value, _ = strings.Cut(env, "=")
+		x.AppendEnv(key, value)
+	}
+
+	return
+}
+
+func (x *CreateContainerArgs) LoadEnvFile(
+	fp string,
+	vars map[string]string,
+) (err error) {
+	var file *os.File
+	if file, err = os.Open(fp); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return x.LoadEnvReader(file, vars)
+}
+
+func envKey(env string) string {
+	var key, _, _ = strings.Cut(env, "=")
+	return key
+}
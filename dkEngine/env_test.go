@@ -0,0 +1,153 @@
+package dkEngine
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestInterpolateEnv(test *testing.T) {
+	var vars = map[string]string{
+		"HOST":  "db.local",
+		"PORT":  "5432",
+		"EMPTY": "",
+	}
+
+	var cases = []struct {
+		value string
+		want  string
+	}{
+		{"plain", "plain"},
+		{"${HOST}", "db.local"},
+		{"${HOST}:${PORT}", "db.local:5432"},
+		{"postgres://${HOST}:${PORT}/app", "postgres://db.local:5432/app"},
+		{"${MISSING}", ""},
+		{"${MISSING:-fallback}", "fallback"},
+		{"${EMPTY:-fallback}", "fallback"},
+		{"${HOST:-fallback}", "db.local"},
+		{"${MISSING:-}", ""},
+		{"$$HOST", "$HOST"},
+		{"$${HOST}", "${HOST}"},
+		{"$HOST", "$HOST"},
+		{"price$", "price$"},
+	}
+
+	for _, c := range cases {
+		test.Run(c.value, func(t *testing.T) {
+			var got, err = InterpolateEnv(c.value, vars)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestInterpolateEnvInvalid(test *testing.T) {
+	var cases = []string{
+		"${HOST",
+		"${}",
+		"${:-default}",
+	}
+
+	for _, value := range cases {
+		test.Run(value, func(t *testing.T) {
+			if got, err := InterpolateEnv(value, nil); err == nil {
+				t.Fatalf("expected error, got %q", got)
+			}
+		})
+	}
+}
+
+func TestParseEnvFile(test *testing.T) {
+	var vars = map[string]string{
+		"HOME": "/root",
+		"USER": "app",
+	}
+
+	var cases = []struct {
+		name string
+		file string
+		want []string
+	}{
+		{
+			name: "comments and blank lines",
+			file: "# comment\n\nA=1\n  # indented comment\n\tB=2\n",
+			want: []string{"A=1", "B=2"},
+		},
+		{
+			name: "value kept as is",
+			file: "QUOTED=\"a b\"\nSPACES=  x  \nEQUALS=a=b\nEMPTY=\n",
+			want: []string{"QUOTED=\"a b\"", "SPACES=  x  ", "EQUALS=a=b", "EMPTY="},
+		},
+		{
+			name: "key only takes value from vars",
+			file: "USER\nMISSING\n",
+			want: []string{"USER=app"},
+		},
+		{
+			name: "interpolation",
+			file: "DATA=${HOME}/data\nLEVEL=${LOG_LEVEL:-info}\n",
+			want: []string{"DATA=/root/data", "LEVEL=info"},
+		},
+		{
+			name: "order and duplicates preserved",
+			file: "A=1\nB=2\nA=3\n",
+			want: []string{"A=1", "B=2", "A=3"},
+		},
+		{
+			name: "crlf",
+			file: "A=1\r\nB=2\r\n",
+			want: []string{"A=1", "B=2"},
+		},
+	}
+
+	for _, c := range cases {
+		test.Run(c.name, func(t *testing.T) {
+			var got, err = ParseEnvFile(strings.NewReader(c.file), vars)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if !slices.Equal(got, c.want) {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseEnvFileInvalid(test *testing.T) {
+	var cases = []string{
+		"=value",
+		"MY KEY=value",
+		"A=${UNTERMINATED",
+	}
+
+	for _, file := range cases {
+		test.Run(file, func(t *testing.T) {
+			var _, err = ParseEnvFile(strings.NewReader(file), nil)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+
+			if !isErrorCode(err, ErrInvalidEnvFile) {
+				t.Fatalf("unexpected error code: %s", err.Error())
+			}
+		})
+	}
+}
+
+func TestAppendEnv(test *testing.T) {
+	var args = NewCreateContainerArgs("api", "bridge", "nginx", PlatformLinuxAmd64)
+	args.AppendEnv("A", "1")
+	args.AppendEnv("B", "2")
+	args.AppendEnv("A", "3")
+	args.RemoveEnv("B")
+
+	if want := []string{"A=3"}; !slices.Equal(args.Args.Env, want) {
+		test.Fatalf("got %q, want %q", args.Args.Env, want)
+	}
+}
@@ -0,0 +1,312 @@
+package dkEngine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventTypeContainer EventType = "container"
+	EventTypeImage     EventType = "image"
+	EventTypeNetwork   EventType = "network"
+	EventTypeVolume    EventType = "volume"
+	EventTypeDaemon    EventType = "daemon"
+	EventTypePlugin    EventType = "plugin"
+	EventTypeBuilder   EventType = "builder"
+)
+
+func (x EventType) String() string {
+	return string(x)
+}
+
+// EventAction
+// 자주 사용하는 action 만 정의함
+// https://docs.docker.com/reference/api/engine/version/v1.47/#tag/System/operation/SystemEvents
+type EventAction string
+
+const (
+	EventActionCreate       EventAction = "create"
+	EventActionStart        EventAction = "start"
+	EventActionStop         EventAction = "stop"
+	EventActionKill         EventAction = "kill"
+	EventActionDie          EventAction = "die"
+	EventActionOom          EventAction = "oom"
+	EventActionRestart      EventAction = "restart"
+	EventActionDestroy      EventAction = "destroy"
+	EventActionHealthStatus EventAction = "health_status"
+	EventActionPull         EventAction = "pull"
+	EventActionConnect      EventAction = "connect"
+	EventActionDisconnect   EventAction = "disconnect"
+)
+
+func (x EventAction) String() string {
+	return string(x)
+}
+
+type Event struct {
+	Type     EventType   `json:"Type"`
+	Action   EventAction `json:"Action"`
+	Actor    *EventActor `json:"Actor"`
+	Scope    string      `json:"scope"`
+	Time     int64       `json:"time"`
+	TimeNano int64       `json:"timeNano"`
+}
+
+type EventActor struct {
+	ID         string            `json:"ID"`
+	Attributes map[string]string `json:"Attributes"`
+}
+
+// Is
+// health_status 이벤트는 action 이 "health_status: healthy" 처럼 상태를 포함하므로 앞부분만 비교한다
+func (x *Event) Is(action EventAction) bool {
+	var name, _, _ = strings.Cut(x.Action.String(), ":")
+	return name == action.String()
+}
+
+// HealthStatus
+// health_status 이벤트의 상태 (healthy, unhealthy, starting)
+func (x *Event) HealthStatus() string {
+	var _, status, _ = strings.Cut(x.Action.String(), ":")
+	return strings.TrimSpace(status)
+}
+
+func (x *Event) At() time.Time {
+	return time.Unix(0, x.TimeNano)
+}
+
+func (x *Event) key() string {
+	var id = ""
+	if x.Actor != nil {
+		id = x.Actor.ID
+	}
+	return fmt.Sprintf("%s/%s/%s/%d", x.Type, x.Action, id, x.TimeNano)
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type EventsOptions struct {
+	Since *time.Time
+	Until *time.Time
+	// Types ex) container, image, network
+	Types []EventType
+	// Containers 컨테이너 이름 또는 id
+	Containers []string
+	// Labels "key" 또는 "key=value"
+	Labels []string
+	// Events ex) die, oom, health_status
+	Events []EventAction
+	// RetryInterval 연결이 끊어졌을 때 다시 연결하기 전 기다리는 시간, 기본값 1초
+	RetryInterval time.Duration
+}
+
+func (x *EventsOptions) query(since *time.Time) (res string, err error) {
+	var filters = Filters{}
+	for _, v := range x.Types {
+		filters.Add("type", v.String())
+	}
+
+	filters.Add("container", x.Containers...)
+	filters.Add("label", x.Labels...)
+
+	for _, v := range x.Events {
+		filters.Add("event", v.String())
+	}
+
+	var values = url.Values{}
+
+	var strFilters string
+	if strFilters, err = filters.Encode(); err != nil {
+		return
+	}
+
+	if strFilters != "" {
+		values.Set("filters", strFilters)
+	}
+
+	if since != nil {
+		values.Set("since", unixNanoString(*since))
+	}
+
+	if x.Until != nil {
+		values.Set("until", unixNanoString(*x.Until))
+	}
+
+	res = values.Encode()
+	return
+}
+
+func unixNanoString(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
+
+// Events
+// /events 를 구독하여 이벤트를 순서대로 전달한다
+// 연결이 끊어지면 마지막 이벤트 시각부터 다시 연결하며, 연결 중 발생한 에러는 (nil, err) 로 전달된다
+// Since 가 없으면 처음 연결할 때의 도커 엔진 시각부터 받는다
+// ctx 가 종료되거나, Until 까지 모두 받았거나, 반복을 중단하면 종료된다
+//
+//	for event, err := range Events(ctx, host, &EventsOptions{Events: []EventAction{EventActionDie}}) {
+//		...
+//	}
+func Events(
+	ctx context.Context,
+	host string,
+	opts *EventsOptions,
+) iter.Seq2[*Event, error] {
+	if opts == nil {
+		opts = &EventsOptions{}
+	}
+
+	var interval = opts.RetryInterval
+	if interval == 0 {
+		interval = time.Second
+	}
+
+	return func(yield func(*Event, error) bool) {
+		var since = opts.Since
+		var seen = make(map[string]bool)
+
+		for {
+			var done bool
+			var err error
+			if since == nil {
+				since, err = eventsStartTime(host)
+			}
+
+			if err == nil {
+				done, err = streamEvents(ctx, host, opts, since, func(event *Event) bool {
+					var at = event.At()
+					if at.Before(*since) {
+						return true
+					}
+
+					// since 는 같은 시각의 이벤트를 포함하므로 다시 연결했을 때 중복을 걸러낸다
+					if !at.Equal(*since) {
+						seen = make(map[string]bool)
+					}
+
+					var key = event.key()
+					if seen[key] {
+						return true
+					}
+
+					seen[key] = true
+					since = &at
+					return yield(event, nil)
+				})
+			}
+
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil && !yield(nil, err) {
+				return
+			}
+
+			if done {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+		}
+	}
+}
+
+// eventsStartTime
+// 다시 연결하는 동안의 이벤트를 놓치지 않도록 도커 엔진의 현재 시각을 since 로 사용한다
+// 클라이언트와 도커 엔진의 시계가 다를 수 있으므로 로컬 시각은 엔진이 시각을 주지 않을 때만 사용한다
+func eventsStartTime(host string) (since *time.Time, err error) {
+	var info *EngineInfo
+	if info, err = Info(host); err != nil {
+		return
+	}
+
+	var now = info.SystemTime
+	if now.IsZero() {
+		now = time.Now()
+	}
+
+	since = &now
+	return
+}
+
+// streamEvents
+// 스트림이 정상적으로 끝났거나 (Until) 반복이 중단되면 done 을 반환한다
+func streamEvents(
+	ctx context.Context,
+	host string,
+	opts *EventsOptions,
+	since *time.Time,
+	fn func(event *Event) bool,
+) (done bool, err error) {
+	var query string
+	if query, err = opts.query(since); err != nil {
+		done = true
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/events?%s", host, query),
+		nil,
+	); err != nil {
+		done = true
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case 200:
+	case 400:
+		done = true
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+
+	var decoder = json.NewDecoder(resp.Body)
+	for {
+		var event = &Event{}
+		if err = decoder.Decode(event); err != nil {
+			if err == io.EOF {
+				err = nil
+				done = opts.Until != nil
+			}
+			return
+		}
+
+		if !fn(event) {
+			done = true
+			return
+		}
+	}
+}
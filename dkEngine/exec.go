@@ -2,6 +2,7 @@ package dkEngine
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/d3v-friends/go-tools/fnError"
@@ -30,6 +31,7 @@ type ExecResponse struct {
 }
 
 func Exec(
+	ctx context.Context,
 	host string,
 	id string,
 	args *ExecRequest,
@@ -40,7 +42,8 @@ func Exec(
 	}
 
 	var request *http.Request
-	if request, err = http.NewRequest(
+	if request, err = http.NewRequestWithContext(
+		ctx,
 		http.MethodPost,
 		fmt.Sprintf(
 			"%s/containers/%s/exec",
@@ -74,3 +77,102 @@ func Exec(
 		return
 	}
 }
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type ExecStartRequest struct {
+	Detach bool `json:"Detach"`
+	Tty    bool `json:"Tty"`
+}
+
+// ExecStart
+// Exec 로 생성한 명령을 실행하고 종료될 때까지 기다린 뒤 stdout, stderr 출력을 반환한다
+// 종료 코드는 InspectExec 로 확인한다, 명령이 끝나지 않으면 ctx 가 종료될 때까지 기다린다
+func ExecStart(
+	ctx context.Context,
+	host string,
+	execId string,
+	tty bool,
+) (output string, err error) {
+	var body []byte
+	if body, err = json.Marshal(&ExecStartRequest{
+		Detach: false,
+		Tty:    tty,
+	}); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/exec/%s/start", host, execId),
+		bytes.NewReader(body),
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationJson)
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case 200:
+		return readStdStream(resp.Body, tty)
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type ExecInspection struct {
+	ID          string `json:"ID"`
+	ContainerID string `json:"ContainerID"`
+	Running     bool   `json:"Running"`
+	ExitCode    *int   `json:"ExitCode"`
+	Pid         int    `json:"Pid"`
+}
+
+func InspectExec(
+	ctx context.Context,
+	host string,
+	execId string,
+) (res *ExecInspection, err error) {
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/exec/%s/json", host, execId),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		res = &ExecInspection{}
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
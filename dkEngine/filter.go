@@ -0,0 +1,41 @@
+package dkEngine
+
+import (
+	"encoding/json"
+)
+
+// Filters
+// 목록 조회, prune, events 에서 사용하는 filters 파라메터
+// ex) {"label": ["app=api"], "dangling": ["true"]}
+type Filters map[string][]string
+
+func (x Filters) Add(key string, values ...string) Filters {
+	for _, value := range values {
+		x[key] = append(x[key], value)
+	}
+	return x
+}
+
+// Encode
+// 필터가 없으면 빈 문자열을 반환한다
+func (x Filters) Encode() (res string, err error) {
+	var filters = make(map[string][]string)
+	for key, values := range x {
+		if len(values) == 0 {
+			continue
+		}
+		filters[key] = values
+	}
+
+	if len(filters) == 0 {
+		return
+	}
+
+	var body []byte
+	if body, err = json.Marshal(filters); err != nil {
+		return
+	}
+
+	res = string(body)
+	return
+}
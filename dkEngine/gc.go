@@ -0,0 +1,298 @@
+package dkEngine
+
+import (
+	"github.com/d3v-friends/go-docker/dkReference"
+	"github.com/d3v-friends/go-tools/fnError"
+	"sort"
+	"time"
+)
+
+type ImageGcPolicy struct {
+	// KeepRecent 저장소별로 최근에 생성된 N 개의 이미지는 남긴다
+	KeepRecent int
+	// MaxAge 생성된지 MaxAge 이상 지난 이미지만 삭제한다
+	MaxAge time.Duration
+	// Repositories 비어있으면 모든 저장소와 태그가 없는 이미지가 대상이다
+	Repositories []string
+	// DryRun 삭제하지 않고 결과만 반환한다
+	DryRun bool
+}
+
+type ImageGcReason string
+
+const (
+	ImageGcReasonInUse    ImageGcReason = "in_use"
+	ImageGcReasonRecent   ImageGcReason = "recent"
+	ImageGcReasonNotOld   ImageGcReason = "not_old"
+	ImageGcReasonExpired  ImageGcReason = "expired"
+	ImageGcReasonDangling ImageGcReason = "dangling"
+)
+
+type ImageGcItem struct {
+	Id       string        `json:"id"`
+	RepoTags []string      `json:"repoTags"`
+	Size     int64         `json:"size"`
+	Created  time.Time     `json:"created"`
+	Reason   ImageGcReason `json:"reason"`
+	// Untagged 정책 대상이 아닌 태그가 있어 이미지는 남기고 대상 태그만 제거한 경우 제거한 태그
+	Untagged []string `json:"untagged,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type ImageGcReport struct {
+	DryRun  bool           `json:"dryRun"`
+	Deleted []*ImageGcItem `json:"deleted"`
+	Kept    []*ImageGcItem `json:"kept"`
+	// SpaceReclaimed 삭제된 이미지의 Size 합계
+	// Size 는 다른 이미지와 공유하는 레이어를 포함하므로 실제로 확보되는 용량보다 클 수 있다
+	SpaceReclaimed int64 `json:"spaceReclaimed"`
+}
+
+// GcImages
+// 정책에 따라 로컬 이미지를 정리한다
+// - 컨테이너 (정지된 컨테이너 포함) 가 사용중인 이미지는 삭제하지 않는다
+// - 저장소별로 KeepRecent 개의 최근 이미지는 삭제하지 않는다
+// - MaxAge 가 있으면 그보다 오래된 이미지만 삭제한다
+// - 정책 대상이 아닌 저장소의 태그가 함께 있는 이미지는 대상 태그만 제거한다
+// - 태그 없이 다이제스트만 있는 이미지는 다이제스트의 저장소로 세며, 모든 다이제스트가 대상 저장소일 때만 삭제한다
+// 삭제에 실패한 이미지는 Error 에 기록하고 계속 진행한다
+func GcImages(
+	host string,
+	policy *ImageGcPolicy,
+) (res *ImageGcReport, err error) {
+	if policy == nil || (policy.KeepRecent <= 0 && policy.MaxAge <= 0) {
+		err = fnError.NewFields(ErrInvalidGcPolicy, map[string]any{
+			"message": "KeepRecent or MaxAge is required",
+		})
+		return
+	}
+
+	var repositories = make(map[string]bool)
+	for _, repository := range policy.Repositories {
+		var ref *dkReference.Reference
+		if ref, err = dkReference.Parse(repository); err != nil {
+			return
+		}
+		repositories[ref.Name()] = true
+	}
+
+	var containers Containers
+	if containers, err = QueryContainers(host); err != nil {
+		return
+	}
+
+	var inUse = make(map[string]bool)
+	for _, container := range containers {
+		inUse[container.ImageID] = true
+	}
+
+	var images Images
+	if images, err = QueryImages(host, nil); err != nil {
+		return
+	}
+
+	var targets []*imageGcTarget
+	res = &ImageGcReport{
+		DryRun: policy.DryRun,
+	}
+	targets, res.Kept = selectGcImages(images, inUse, repositories, policy, time.Now())
+	res.Deleted = make([]*ImageGcItem, 0, len(targets))
+
+	for _, target := range targets {
+		var item = target.item
+		res.Deleted = append(res.Deleted, item)
+		if policy.DryRun {
+			if target.removesImage {
+				res.SpaceReclaimed += item.Size
+			}
+			continue
+		}
+
+		// 태그가 없는 이미지는 ID 로 삭제한다
+		if len(target.tags) == 0 {
+			if _, removeErr := RemoveImage(host, item.Id, false, false); removeErr != nil {
+				item.Error = removeErr.Error()
+				continue
+			}
+			res.SpaceReclaimed += item.Size
+			continue
+		}
+
+		// force 없이 태그를 하나씩 제거한다, 마지막 태그가 제거되면 이미지도 삭제된다
+		var failed = false
+		for _, tag := range target.tags {
+			if _, removeErr := RemoveImage(host, tag, false, false); removeErr != nil {
+				item.Error = removeErr.Error()
+				failed = true
+				break
+			}
+		}
+
+		if failed || !target.removesImage {
+			continue
+		}
+
+		res.SpaceReclaimed += item.Size
+	}
+
+	return
+}
+
+type imageGcTarget struct {
+	item *ImageGcItem
+	// tags 제거할 태그, 비어있으면 이미지를 ID 로 삭제한다
+	tags []string
+	// removesImage 대상 태그를 모두 제거하면 이미지도 삭제되는지 여부
+	removesImage bool
+	dangling     bool
+}
+
+// selectGcImages
+// 정책에 따라 삭제할 이미지와 남길 이미지를 고른다
+// repositories 는 정책 대상 저장소 (Reference.Name), 비어있으면 모든 저장소와 태그가 없는 이미지가 대상이다
+// 태그 없이 다이제스트만 있는 이미지는 다이제스트의 저장소로 센다
+func selectGcImages(
+	images Images,
+	inUse map[string]bool,
+	repositories map[string]bool,
+	policy *ImageGcPolicy,
+	now time.Time,
+) (targets []*imageGcTarget, kept []*ImageGcItem) {
+	var inScope = func(raw string) (name string, ok bool) {
+		var ref, err = dkReference.Parse(raw)
+		if err != nil {
+			return
+		}
+		name = ref.Name()
+		ok = len(repositories) == 0 || repositories[name]
+		return
+	}
+
+	// 저장소별 이미지 목록, 같은 저장소의 여러 태그가 붙은 이미지는 한번만 센다
+	var byRepository = make(map[string]map[string]*Image)
+	var addRepository = func(name string, image *Image) {
+		if byRepository[name] == nil {
+			byRepository[name] = make(map[string]*Image)
+		}
+		byRepository[name][image.Id] = image
+	}
+
+	var candidates = make([]*imageGcTarget, 0)
+	for _, image := range images {
+		var target = &imageGcTarget{
+			item: &ImageGcItem{
+				Id:       image.Id,
+				RepoTags: image.RepoTags,
+				Size:     image.Size,
+				Created:  image.CreatedAt(),
+			},
+		}
+
+		var tags = nonEmptyTags(image.RepoTags)
+		switch {
+		case image.IsDangling():
+			if len(repositories) != 0 {
+				continue
+			}
+			target.removesImage = true
+			target.dangling = true
+		case len(tags) == 0:
+			// 다이제스트만 있는 이미지는 ID 로 삭제하므로 모든 다이제스트의 저장소가 대상일 때만 삭제한다
+			var names = make([]string, 0, len(image.RepoDigests))
+			var outOfScope = false
+			for _, repoDigest := range image.RepoDigests {
+				var name, ok = inScope(repoDigest)
+				switch {
+				case ok:
+					names = append(names, name)
+				case name != "":
+					outOfScope = true
+				}
+			}
+
+			if len(names) == 0 || outOfScope {
+				continue
+			}
+
+			for _, name := range names {
+				addRepository(name, image)
+			}
+			target.removesImage = true
+		default:
+			for _, repoTag := range tags {
+				var name, ok = inScope(repoTag)
+				if !ok {
+					continue
+				}
+				target.tags = append(target.tags, repoTag)
+				addRepository(name, image)
+			}
+
+			if len(target.tags) == 0 {
+				continue
+			}
+			target.removesImage = len(target.tags) == len(tags)
+		}
+
+		candidates = append(candidates, target)
+	}
+
+	var recent = make(map[string]bool)
+	for _, m := range byRepository {
+		var ls = make(Images, 0, len(m))
+		for _, image := range m {
+			ls = append(ls, image)
+		}
+
+		sort.SliceStable(ls, func(i, j int) bool {
+			return ls[i].Created > ls[j].Created
+		})
+
+		for i := 0; i < len(ls) && i < policy.KeepRecent; i++ {
+			recent[ls[i].Id] = true
+		}
+	}
+
+	targets = make([]*imageGcTarget, 0, len(candidates))
+	kept = make([]*ImageGcItem, 0)
+	for _, target := range candidates {
+		var item = target.item
+		switch {
+		case inUse[item.Id]:
+			item.Reason = ImageGcReasonInUse
+		case recent[item.Id]:
+			item.Reason = ImageGcReasonRecent
+		case policy.MaxAge > 0 && now.Sub(item.Created) < policy.MaxAge:
+			item.Reason = ImageGcReasonNotOld
+		}
+
+		if item.Reason != "" {
+			kept = append(kept, item)
+			continue
+		}
+
+		item.Reason = ImageGcReasonExpired
+		if target.dangling {
+			item.Reason = ImageGcReasonDangling
+		}
+
+		// 정책 대상이 아닌 태그가 남아있으면 대상 태그만 제거하고 이미지는 남긴다
+		if !target.removesImage {
+			item.Untagged = target.tags
+		}
+
+		targets = append(targets, target)
+	}
+
+	return
+}
+
+func nonEmptyTags(repoTags []string) (ls []string) {
+	ls = make([]string, 0, len(repoTags))
+	for _, tag := range repoTags {
+		if tag != "<none>:<none>" {
+			ls = append(ls, tag)
+		}
+	}
+	return
+}
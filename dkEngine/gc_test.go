@@ -0,0 +1,126 @@
+package dkEngine
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSelectGcImages(test *testing.T) {
+	var now = time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)
+	var daysAgo = func(days int) int64 {
+		return now.AddDate(0, 0, -days).Unix()
+	}
+
+	var digest = func(hex string) string {
+		return "sha256:" + strings.Repeat(hex, 64)
+	}
+
+	var images = Images{
+		{Id: "api-1", RepoTags: []string{"api:v1"}, Created: daysAgo(30)},
+		{Id: "api-2", RepoTags: []string{"api:v2", "api:stable"}, Created: daysAgo(20)},
+		{Id: "api-3", RepoTags: []string{"api:v3"}, Created: daysAgo(10)},
+		{Id: "api-4", RepoDigests: []string{"api@" + digest("4")}, Created: daysAgo(5)},
+		{Id: "api-5", RepoTags: []string{"<none>:<none>"}, RepoDigests: []string{"api@" + digest("5")}, Created: daysAgo(40)},
+		{Id: "web-1", RepoTags: []string{"web:v1", "api:web"}, Created: daysAgo(50)},
+		{Id: "web-2", RepoTags: []string{"web:v2"}, Created: daysAgo(1)},
+		{Id: "db-1", RepoDigests: []string{"db@" + digest("1"), "api@" + digest("d")}, Created: daysAgo(60)},
+		{Id: "dangling", RepoTags: []string{"<none>:<none>"}, RepoDigests: []string{"<none>@<none>"}, Created: daysAgo(90)},
+	}
+
+	// 결과는 "id|reason|untagged" 로 비교한다, repositories 는 docker.io/library 저장소 이름이다
+	var cases = []struct {
+		name         string
+		policy       *ImageGcPolicy
+		repositories []string
+		inUse        []string
+		deleted      []string
+		kept         []string
+	}{
+		{
+			name:    "keep recent per repository",
+			policy:  &ImageGcPolicy{KeepRecent: 1},
+			deleted: []string{"api-1|expired|", "api-2|expired|", "api-3|expired|", "api-5|expired|", "web-1|expired|", "dangling|dangling|"},
+			kept:    []string{"api-4|recent|", "web-2|recent|", "db-1|recent|"},
+		},
+		{
+			name:    "digest only images count toward keep recent",
+			policy:  &ImageGcPolicy{KeepRecent: 2},
+			deleted: []string{"api-1|expired|", "api-2|expired|", "api-5|expired|", "dangling|dangling|"},
+			kept:    []string{"api-3|recent|", "api-4|recent|", "web-1|recent|", "web-2|recent|", "db-1|recent|"},
+		},
+		{
+			name:    "max age",
+			policy:  &ImageGcPolicy{MaxAge: 25 * 24 * time.Hour},
+			deleted: []string{"api-1|expired|", "api-5|expired|", "web-1|expired|", "db-1|expired|", "dangling|dangling|"},
+			kept:    []string{"api-2|not_old|", "api-3|not_old|", "api-4|not_old|", "web-2|not_old|"},
+		},
+		{
+			name:    "in use",
+			policy:  &ImageGcPolicy{MaxAge: time.Hour},
+			inUse:   []string{"api-1", "dangling"},
+			deleted: []string{"api-2|expired|", "api-3|expired|", "api-4|expired|", "api-5|expired|", "web-1|expired|", "web-2|expired|", "db-1|expired|"},
+			kept:    []string{"api-1|in_use|", "dangling|in_use|"},
+		},
+		{
+			name:         "repositories scope",
+			policy:       &ImageGcPolicy{KeepRecent: 1},
+			repositories: []string{"api"},
+			deleted:      []string{"api-1|expired|", "api-2|expired|", "api-3|expired|", "api-5|expired|", "web-1|expired|api:web"},
+			kept:         []string{"api-4|recent|"},
+		},
+		{
+			name:         "digest only image with other repository is not a target",
+			policy:       &ImageGcPolicy{MaxAge: time.Hour},
+			repositories: []string{"db"},
+			deleted:      []string{},
+			kept:         []string{},
+		},
+		{
+			name:         "digest only image with all repositories in scope",
+			policy:       &ImageGcPolicy{MaxAge: time.Hour},
+			repositories: []string{"db", "api"},
+			deleted:      []string{"api-1|expired|", "api-2|expired|", "api-3|expired|", "api-4|expired|", "api-5|expired|", "web-1|expired|api:web", "db-1|expired|"},
+			kept:         []string{},
+		},
+	}
+
+	var format = func(items []*ImageGcItem) (ls []string) {
+		ls = make([]string, len(items))
+		for i, item := range items {
+			ls[i] = fmt.Sprintf("%s|%s|%s", item.Id, item.Reason, strings.Join(item.Untagged, ","))
+		}
+		return
+	}
+
+	for _, c := range cases {
+		test.Run(c.name, func(t *testing.T) {
+			var repositories = make(map[string]bool)
+			for _, repository := range c.repositories {
+				repositories["docker.io/library/"+repository] = true
+			}
+
+			var inUse = make(map[string]bool)
+			for _, id := range c.inUse {
+				inUse[id] = true
+			}
+
+			var targets, kept = selectGcImages(images, inUse, repositories, c.policy, now)
+
+			var deleted = make([]*ImageGcItem, len(targets))
+			for i, target := range targets {
+				deleted[i] = target.item
+			}
+
+			if got := format(deleted); !slices.Equal(got, c.deleted) {
+				t.Fatalf("deleted: got %q, want %q", got, c.deleted)
+			}
+
+			if got := format(kept); !slices.Equal(got, c.kept) {
+				t.Fatalf("kept: got %q, want %q", got, c.kept)
+			}
+		})
+	}
+}
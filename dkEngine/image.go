@@ -0,0 +1,575 @@
+package dkEngine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-docker/dkReference"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Image struct {
+	Id          string            `json:"Id"`
+	ParentId    string            `json:"ParentId"`
+	RepoTags    []string          `json:"RepoTags"`
+	RepoDigests []string          `json:"RepoDigests"`
+	Created     int64             `json:"Created"`
+	Size        int64             `json:"Size"`
+	SharedSize  int64             `json:"SharedSize"`
+	Labels      map[string]string `json:"Labels"`
+	Containers  int64             `json:"Containers"`
+}
+
+type Images []*Image
+
+func (x *Image) CreatedAt() time.Time {
+	return time.Unix(x.Created, 0)
+}
+
+// IsDangling
+// 태그와 다이제스트가 모두 없는 이미지
+func (x *Image) IsDangling() bool {
+	for _, tag := range x.RepoTags {
+		if tag != "<none>:<none>" {
+			return false
+		}
+	}
+
+	for _, digest := range x.RepoDigests {
+		if digest != "<none>@<none>" {
+			return false
+		}
+	}
+	return true
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type QueryImagesOptions struct {
+	// All 중간 레이어 이미지까지 포함한다
+	All bool
+	// Dangling true 이면 태그가 없는 이미지만, false 이면 태그가 있는 이미지만
+	Dangling *bool
+	// References ex) nginx, nginx:1.*, docker.d3v-friends.com/*
+	References []string
+	// Labels "key" 또는 "key=value"
+	Labels []string
+	// SharedSize 다른 이미지와 공유하는 크기를 계산한다
+	SharedSize bool
+}
+
+func QueryImages(
+	host string,
+	opts *QueryImagesOptions,
+) (ls Images, err error) {
+	if opts == nil {
+		opts = &QueryImagesOptions{}
+	}
+
+	var filters = Filters{}
+	if opts.Dangling != nil {
+		filters.Add("dangling", strconv.FormatBool(*opts.Dangling))
+	}
+	filters.Add("reference", opts.References...)
+	filters.Add("label", opts.Labels...)
+
+	var values = url.Values{}
+	values.Set("all", strconv.FormatBool(opts.All))
+	values.Set("shared-size", strconv.FormatBool(opts.SharedSize))
+
+	var strFilters string
+	if strFilters, err = filters.Encode(); err != nil {
+		return
+	}
+
+	if strFilters != "" {
+		values.Set("filters", strFilters)
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/images/json?%s", host, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 30,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		ls = make(Images, 0)
+		if err = json.NewDecoder(resp.Body).Decode(&ls); err != nil {
+			return
+		}
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// ImageInspection
+// https://docs.docker.com/reference/api/engine/version/v1.47/#tag/Image/operation/ImageInspect
+type ImageInspection struct {
+	Id            string                   `json:"Id"`
+	RepoTags      []string                 `json:"RepoTags"`
+	RepoDigests   []string                 `json:"RepoDigests"`
+	Parent        string                   `json:"Parent"`
+	Comment       string                   `json:"Comment"`
+	Created       string                   `json:"Created"`
+	Author        string                   `json:"Author"`
+	Config        *ImageInspectionConfig   `json:"Config"`
+	Architecture  string                   `json:"Architecture"`
+	Variant       string                   `json:"Variant"`
+	Os            string                   `json:"Os"`
+	Size          int64                    `json:"Size"`
+	RootFS        *ImageInspectionRootFS   `json:"RootFS"`
+	Metadata      *ImageInspectionMetadata `json:"Metadata"`
+	DockerVersion string                   `json:"DockerVersion"`
+}
+
+type ImageInspectionConfig struct {
+	User         string              `json:"User"`
+	Env          []string            `json:"Env"`
+	Cmd          []string            `json:"Cmd"`
+	Entrypoint   []string            `json:"Entrypoint"`
+	WorkingDir   string              `json:"WorkingDir"`
+	Labels       map[string]string   `json:"Labels"`
+	ExposedPorts map[string]struct{} `json:"ExposedPorts"`
+	Volumes      map[string]struct{} `json:"Volumes"`
+	StopSignal   string              `json:"StopSignal"`
+	Healthcheck  *HealthConfig       `json:"Healthcheck"`
+}
+
+type ImageInspectionRootFS struct {
+	Type   string   `json:"Type"`
+	Layers []string `json:"Layers"`
+}
+
+type ImageInspectionMetadata struct {
+	LastTagTime string `json:"LastTagTime"`
+}
+
+// RepoDigest
+// image 의 저장소에 해당하는 digest (sha256:...), pull 하지 않고 로컬에서 빌드한 이미지는 빈 문자열
+func (x *ImageInspection) RepoDigest(image string) (digest string, err error) {
+	var ref *dkReference.Reference
+	if ref, err = dkReference.Parse(image); err != nil {
+		return
+	}
+
+	for _, repoDigest := range x.RepoDigests {
+		var repoRef *dkReference.Reference
+		if repoRef, err = dkReference.Parse(repoDigest); err != nil {
+			return
+		}
+
+		if repoRef.Name() == ref.Name() {
+			digest = repoRef.Digest
+			return
+		}
+	}
+
+	return
+}
+
+func InspectImage(
+	host string,
+	image string,
+) (res *ImageInspection, err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/images/%s/json", host, image),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		res = &ImageInspection{}
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundImage, map[string]any{
+			"image": image,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// TagImage
+// image 에 repo:tag 를 추가한다 ex) TagImage(host, "api:build", "docker.d3v-friends.com/api", "v1.0.0")
+func TagImage(
+	host string,
+	image string,
+	repo string,
+	tag string,
+) (err error) {
+	var values = url.Values{}
+	values.Set("repo", repo)
+	values.Set("tag", tag)
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%s/images/%s/tag?%s", host, image, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200, 201:
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundImage, map[string]any{
+			"image": image,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type RemoveImageResponse struct {
+	Untagged string `json:"Untagged,omitempty"`
+	Deleted  string `json:"Deleted,omitempty"`
+}
+
+// RemoveImage
+// force 는 사용중인 컨테이너가 있어도 삭제하고, noprune 은 태그가 없는 부모 이미지를 남긴다
+func RemoveImage(
+	host string,
+	image string,
+	force bool,
+	noprune bool,
+) (ls []*RemoveImageResponse, err error) {
+	var values = url.Values{}
+	values.Set("force", strconv.FormatBool(force))
+	values.Set("noprune", strconv.FormatBool(noprune))
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodDelete,
+		fmt.Sprintf("%s/images/%s?%s", host, image, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 60,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		ls = make([]*RemoveImageResponse, 0)
+		if err = json.NewDecoder(resp.Body).Decode(&ls); err != nil {
+			return
+		}
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundImage, map[string]any{
+			"image": image,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type ImageHistoryItem struct {
+	Id        string   `json:"Id"`
+	Created   int64    `json:"Created"`
+	CreatedBy string   `json:"CreatedBy"`
+	Tags      []string `json:"Tags"`
+	Size      int64    `json:"Size"`
+	Comment   string   `json:"Comment"`
+}
+
+// CreatedByInstruction
+// CreatedBy 에서 "/bin/sh -c #(nop) " 접두사를 제거한 Dockerfile 명령
+func (x *ImageHistoryItem) CreatedByInstruction() string {
+	return strings.TrimSpace(strings.TrimPrefix(x.CreatedBy, "/bin/sh -c #(nop) "))
+}
+
+func ImageHistory(
+	host string,
+	image string,
+) (ls []*ImageHistoryItem, err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/images/%s/history", host, image),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		ls = make([]*ImageHistoryItem, 0)
+		if err = json.NewDecoder(resp.Body).Decode(&ls); err != nil {
+			return
+		}
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundImage, map[string]any{
+			"image": image,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type PushResult struct {
+	Tag    string `json:"Tag"`
+	Digest string `json:"Digest"`
+	Size   int64  `json:"Size"`
+}
+
+// Push
+// 이미지를 레지스트리에 올리고 digest 를 반환한다
+func Push(
+	ctx context.Context,
+	host string,
+	image string,
+	registry Registry,
+) (digest string, err error) {
+	return PushWithProgress(ctx, host, image, nil, registry)
+}
+
+// PushWithProgress
+// 스트림으로 전달되는 진행 상황을 onProgress 로 전달한다
+// 스트림 중간에 에러 메시지가 오면 에러를 반환한다
+// 업로드 시간에 제한이 없으므로 ctx 로 취소하거나 제한 시간을 둔다
+func PushWithProgress(
+	ctx context.Context,
+	host string,
+	image string,
+	onProgress ProgressFunc,
+	registry Registry,
+) (digest string, err error) {
+	var ref *dkReference.Reference
+	if ref, err = dkReference.Parse(image); err != nil {
+		return
+	}
+
+	var token string
+	if token, err = createRegistryToken(registry); err != nil {
+		return
+	}
+
+	var values = url.Values{}
+	values.Set("tag", ref.TagOrDefault())
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/images/%s/push?%s", host, ref.FamiliarName(), values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationJson)
+	request.Header.Set(xRegistryAuthHeader, token)
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case 200:
+		// 마지막 aux 메시지에 push 된 digest 가 담겨있다
+		if err = readJsonMessages(resp.Body, onProgress, func(aux json.RawMessage) (err error) {
+			var result = &PushResult{}
+			if err = json.Unmarshal(aux, result); err != nil {
+				return
+			}
+
+			if result.Digest != "" {
+				digest = result.Digest
+			}
+			return
+		}); err != nil {
+			return
+		}
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundImage, map[string]any{
+			"image": image,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// SaveImages
+// 이미지를 tar 스트림으로 내려받는다, 사용 후 반드시 Close 해야 한다
+// ctx 가 종료되면 스트림도 끊긴다
+func SaveImages(
+	ctx context.Context,
+	host string,
+	images []string,
+) (res io.ReadCloser, err error) {
+	var values = url.Values{}
+	for _, image := range images {
+		values.Add("names", image)
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/images/get?%s", host, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		res = resp.Body
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		_ = resp.Body.Close()
+		return
+	}
+}
+
+// LoadImages
+// SaveImages 로 받은 tar 스트림을 불러온다
+// 스트림 중간에 에러 메시지가 오면 에러를 반환한다
+func LoadImages(
+	ctx context.Context,
+	host string,
+	reader io.Reader,
+	onProgress ProgressFunc,
+) (err error) {
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodPost,
+		fmt.Sprintf("%s/images/load?quiet=false", host),
+		reader,
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationTar)
+
+	var resp *http.Response
+	if resp, err = http.DefaultClient.Do(request); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case 200:
+		return readJsonMessages(resp.Body, onProgress, nil)
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+// TransferImages
+// 디스크에 저장하지 않고 srcHost 의 이미지를 dstHost 로 옮긴다
+// 전송 시간에 제한이 없으므로 ctx 로 취소하거나 제한 시간을 둔다
+func TransferImages(
+	ctx context.Context,
+	srcHost string,
+	dstHost string,
+	images []string,
+	onProgress ProgressFunc,
+) (err error) {
+	var reader io.ReadCloser
+	if reader, err = SaveImages(ctx, srcHost, images); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = reader.Close()
+	}()
+
+	return LoadImages(ctx, dstHost, reader, onProgress)
+}
@@ -0,0 +1,117 @@
+package dkEngine
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Logs
+// 컨테이너의 stdout, stderr 로그 중 마지막 tail 줄을 반환한다, tail 이 0 이면 전체
+func Logs(
+	ctx context.Context,
+	host string,
+	id string,
+	tail int,
+) (res string, err error) {
+	var inspection *ContainerInspection
+	if inspection, err = inspect(ctx, host, id); err != nil {
+		return
+	}
+
+	var values = url.Values{}
+	values.Set("tail", "all")
+	if tail > 0 {
+		values.Set("tail", strconv.Itoa(tail))
+	}
+
+	var config = fnPointer.Default(inspection.Config, ContainerInspectionConfig{})
+	return readLogs(ctx, host, id, values, config.Tty)
+}
+
+// readLogs
+// values 에는 stdout, stderr 외의 파라메터 (tail, since, timestamps 등) 를 넣는다
+func readLogs(
+	ctx context.Context,
+	host string,
+	id string,
+	values url.Values,
+	tty bool,
+) (res string, err error) {
+	values.Set("stdout", "true")
+	values.Set("stderr", "true")
+
+	var request *http.Request
+	if request, err = http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		fmt.Sprintf("%s/containers/%s/logs?%s", host, id, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 30,
+	}).Do(request); err != nil {
+		return
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	switch resp.StatusCode {
+	case 200:
+		return readStdStream(resp.Body, tty)
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+// readStdStream
+// tty 가 아닌 컨테이너의 출력은 8 byte 헤더 (stream type, 0, 0, 0, size uint32) 로 stdout, stderr 가 섞여서 전달된다
+// https://docs.docker.com/reference/api/engine/version/v1.47/#tag/Container/operation/ContainerAttach
+func readStdStream(
+	reader io.Reader,
+	tty bool,
+) (res string, err error) {
+	if tty {
+		var body []byte
+		if body, err = io.ReadAll(reader); err != nil {
+			return
+		}
+		res = string(body)
+		return
+	}
+
+	var buf = bytes.Buffer{}
+	var header = make([]byte, 8)
+	for {
+		if _, err = io.ReadFull(reader, header); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			break
+		}
+
+		var size = int64(binary.BigEndian.Uint32(header[4:]))
+		if _, err = io.CopyN(&buf, reader, size); err != nil {
+			break
+		}
+	}
+
+	res = buf.String()
+	return
+}
@@ -46,13 +46,20 @@ func QueryNetworks(
 /* ------------------------------------------------------------------------------------------------------------ */
 
 type CreateNetworkRequest struct {
-	Name     string `json:"name"`
-	Driver   string `json:"driver"`
-	Internal bool   `json:"internal"`
+	Name           string            `json:"Name"`
+	CheckDuplicate bool              `json:"CheckDuplicate,omitempty"`
+	Driver         string            `json:"Driver,omitempty"`
+	Internal       bool              `json:"Internal"`
+	Attachable     bool              `json:"Attachable,omitempty"`
+	Ingress        bool              `json:"Ingress,omitempty"`
+	IPAM           *IPAM             `json:"IPAM,omitempty"`
+	EnableIPv6     bool              `json:"EnableIPv6,omitempty"`
+	Options        map[string]string `json:"Options,omitempty"`
+	Labels         map[string]string `json:"Labels,omitempty"`
 }
 
 type CreateNetworkResponse struct {
-	Id      string `json:"Tag"`
+	Id      string `json:"Id"`
 	Warning string `json:"Warning"`
 }
 
@@ -62,12 +69,21 @@ func CreateNetwork(
 	driver string,
 	internal bool,
 ) (res *CreateNetworkResponse, err error) {
-	var body []byte
-	if body, err = json.Marshal(&CreateNetworkRequest{
+	return CreateNetworkWith(host, &CreateNetworkRequest{
 		Name:     name,
 		Driver:   driver,
 		Internal: internal,
-	}); err != nil {
+	})
+}
+
+// CreateNetworkWith
+// IPAM (subnet, gateway, ip range), ipv6, attachable, label, driver option 을 지정하여 네트워크를 생성한다
+func CreateNetworkWith(
+	host string,
+	args *CreateNetworkRequest,
+) (res *CreateNetworkResponse, err error) {
+	var body []byte
+	if body, err = json.Marshal(args); err != nil {
 		return
 	}
 
@@ -95,6 +111,12 @@ func CreateNetwork(
 		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
 			return
 		}
+	case 409:
+		err = fnError.NewFields(ErrAlreadyHasSameNetworkName, map[string]any{
+			"name":    args.Name,
+			"message": string(fnPanic.Value(io.ReadAll(resp.Body))),
+		})
+		return
 	default:
 		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
 		return
@@ -105,6 +127,48 @@ func CreateNetwork(
 
 /* ------------------------------------------------------------------------------------------------------------ */
 
+// InspectNetwork
+// networkId 는 id 또는 이름, 연결된 컨테이너의 endpoint 정보가 Containers 에 포함된다
+func InspectNetwork(
+	host string,
+	networkId string,
+) (res *Network, err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/networks/%s", host, networkId),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		res = &Network{}
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundNetwork, map[string]any{
+			"network": networkId,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
 func DeleteNetwork(
 	host string,
 	networkName string,
@@ -133,3 +197,102 @@ func DeleteNetwork(
 	}
 	return
 }
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type ConnectNetworkRequest struct {
+	Container      string            `json:"Container"`
+	EndpointConfig *EndpointSettings `json:"EndpointConfig,omitempty"`
+}
+
+// ConnectNetwork
+// 실행중인 컨테이너를 네트워크에 연결한다
+// settings 에 Aliases, IPAMConfig 를 지정할 수 있으며 nil 이면 기본값으로 연결된다
+func ConnectNetwork(
+	host string,
+	networkId string,
+	containerId string,
+	settings *EndpointSettings,
+) (err error) {
+	var body []byte
+	if body, err = json.Marshal(&ConnectNetworkRequest{
+		Container:      containerId,
+		EndpointConfig: settings,
+	}); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%s/networks/%s/connect", host, networkId),
+		bytes.NewReader(body),
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationJson)
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type DisconnectNetworkRequest struct {
+	Container string `json:"Container"`
+	Force     bool   `json:"Force"`
+}
+
+func DisconnectNetwork(
+	host string,
+	networkId string,
+	containerId string,
+	force bool,
+) (err error) {
+	var body []byte
+	if body, err = json.Marshal(&DisconnectNetworkRequest{
+		Container: containerId,
+		Force:     force,
+	}); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%s/networks/%s/disconnect", host, networkId),
+		bytes.NewReader(body),
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationJson)
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
@@ -0,0 +1,204 @@
+package dkEngine
+
+import (
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"strconv"
+	"strings"
+)
+
+type PortProtocol string
+
+const (
+	PortProtocolTcp  PortProtocol = "tcp"
+	PortProtocolUdp  PortProtocol = "udp"
+	PortProtocolSctp PortProtocol = "sctp"
+)
+
+var PortProtocolAll = []PortProtocol{
+	PortProtocolTcp,
+	PortProtocolUdp,
+	PortProtocolSctp,
+}
+
+func (x PortProtocol) String() string {
+	return strings.ToLower(string(x))
+}
+
+func (x PortProtocol) IsValid() bool {
+	for _, protocol := range PortProtocolAll {
+		if protocol == x {
+			return true
+		}
+	}
+	return false
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// PortBindSpec
+// 컨테이너 포트 하나에 대한 바인딩 정보
+// HostIp 가 비어있으면 모든 인터페이스 (0.0.0.0, ::) 에 바인딩된다
+// HostPort 가 비어있으면 도커가 임의의 포트를 할당하고, "8000-8010" 처럼 범위를 지정하면 그 안에서 할당한다
+type PortBindSpec struct {
+	HostIp        string
+	HostPort      string
+	ContainerPort uint64
+	Protocol      PortProtocol
+}
+
+// Key
+// ExposedPorts, PortBindings 에서 사용하는 "80/tcp" 형식의 키
+func (x *PortBindSpec) Key() string {
+	return portKey(x.ContainerPort, x.Protocol)
+}
+
+func portKey(container uint64, protocol PortProtocol) string {
+	return fmt.Sprintf("%d/%s", container, protocol.String())
+}
+
+// ParsePortBindSpec
+// docker cli 의 -p 옵션과 같은 형식을 해석한다
+// [hostIp:][hostPort:]containerPort[/protocol]
+// ex) 80, 8080:80, 127.0.0.1:8080:80/udp, 127.0.0.1::80, [::1]:8080:80, 8000-8010:80-90
+// 범위가 지정된 경우 컨테이너 포트 하나당 하나의 PortBindSpec 으로 펼쳐진다
+func ParsePortBindSpec(raw string) (ls []*PortBindSpec, err error) {
+	var protocol = PortProtocolTcp
+	var body = raw
+	if idx := strings.LastIndex(body, "/"); idx != -1 {
+		protocol = PortProtocol(strings.ToLower(body[idx+1:]))
+		body = body[:idx]
+	}
+
+	if !protocol.IsValid() {
+		err = fnError.NewFields(ErrInvalidPortBindSpec, map[string]any{
+			"spec":     raw,
+			"protocol": protocol,
+		})
+		return
+	}
+
+	var hostIp, rawHost, rawContainer string
+	if strings.HasPrefix(body, "[") {
+		var end = strings.Index(body, "]")
+		if end == -1 || len(body) <= end+1 || body[end+1] != ':' {
+			err = fnError.NewFields(ErrInvalidPortBindSpec, map[string]any{
+				"spec": raw,
+			})
+			return
+		}
+		hostIp = body[1:end]
+		body = body[end+2:]
+
+		var parts = strings.Split(body, ":")
+		if len(parts) != 2 {
+			err = fnError.NewFields(ErrInvalidPortBindSpec, map[string]any{
+				"spec": raw,
+			})
+			return
+		}
+		rawHost, rawContainer = parts[0], parts[1]
+	} else {
+		var parts = strings.Split(body, ":")
+		switch len(parts) {
+		case 1:
+			rawContainer = parts[0]
+		case 2:
+			rawHost, rawContainer = parts[0], parts[1]
+		case 3:
+			hostIp, rawHost, rawContainer = parts[0], parts[1], parts[2]
+		default:
+			err = fnError.NewFields(ErrInvalidPortBindSpec, map[string]any{
+				"spec": raw,
+			})
+			return
+		}
+	}
+
+	var containerStart, containerEnd uint64
+	if containerStart, containerEnd, err = parsePortRange(rawContainer); err != nil {
+		err = fnError.NewFields(ErrInvalidPortBindSpec, map[string]any{
+			"spec":  raw,
+			"error": err.Error(),
+		})
+		return
+	}
+
+	var hostStart, hostEnd uint64
+	if rawHost != "" {
+		if hostStart, hostEnd, err = parsePortRange(rawHost); err != nil {
+			err = fnError.NewFields(ErrInvalidPortBindSpec, map[string]any{
+				"spec":  raw,
+				"error": err.Error(),
+			})
+			return
+		}
+	}
+
+	var containerSize = containerEnd - containerStart
+	var hostSize = hostEnd - hostStart
+
+	// 컨테이너 포트가 하나이면 호스트 포트 범위 안에서 도커가 할당한다
+	// 컨테이너 포트가 범위이면 호스트 포트 범위는 같은 크기여야 한다
+	if rawHost != "" && containerSize != 0 && hostSize != containerSize {
+		err = fnError.NewFields(ErrInvalidPortBindSpec, map[string]any{
+			"spec": raw,
+		})
+		return
+	}
+
+	ls = make([]*PortBindSpec, 0, containerSize+1)
+	for i := uint64(0); i <= containerSize; i++ {
+		var hostPort = ""
+		switch {
+		case rawHost == "":
+		case containerSize == 0:
+			hostPort = rawHost
+		default:
+			hostPort = strconv.FormatUint(hostStart+i, 10)
+		}
+
+		ls = append(ls, &PortBindSpec{
+			HostIp:        hostIp,
+			HostPort:      hostPort,
+			ContainerPort: containerStart + i,
+			Protocol:      protocol,
+		})
+	}
+
+	return
+}
+
+func parsePortRange(raw string) (start, end uint64, err error) {
+	var parts = strings.SplitN(raw, "-", 2)
+	if start, err = parsePort(parts[0]); err != nil {
+		return
+	}
+
+	end = start
+	if len(parts) == 2 {
+		if end, err = parsePort(parts[1]); err != nil {
+			return
+		}
+	}
+
+	if end < start {
+		err = fnError.NewF("invalid port range: %s", raw)
+		return
+	}
+
+	return
+}
+
+func parsePort(raw string) (port uint64, err error) {
+	if port, err = strconv.ParseUint(raw, 10, 16); err != nil {
+		return
+	}
+
+	if port == 0 {
+		err = fnError.NewF("invalid port: %s", raw)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,73 @@
+package dkEngine
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+func TestParsePortBindSpec(test *testing.T) {
+	// 결과는 "hostIp|hostPort|containerPort/protocol" 로 비교한다
+	var cases = []struct {
+		raw  string
+		want []string
+	}{
+		{"80", []string{"||80/tcp"}},
+		{"80/udp", []string{"||80/udp"}},
+		{"80/SCTP", []string{"||80/sctp"}},
+		{"8080:80", []string{"|8080|80/tcp"}},
+		{"127.0.0.1:8080:80/udp", []string{"127.0.0.1|8080|80/udp"}},
+		{"127.0.0.1::80", []string{"127.0.0.1||80/tcp"}},
+		{"[::1]:8080:80", []string{"::1|8080|80/tcp"}},
+		{"[::1]::80", []string{"::1||80/tcp"}},
+		{"8000-8010:80", []string{"|8000-8010|80/tcp"}},
+		{"8000-8002:80-82", []string{"|8000|80/tcp", "|8001|81/tcp", "|8002|82/tcp"}},
+		{"80-81", []string{"||80/tcp", "||81/tcp"}},
+	}
+
+	for _, c := range cases {
+		test.Run(c.raw, func(t *testing.T) {
+			var ls, err = ParsePortBindSpec(c.raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			var got = make([]string, len(ls))
+			for i, spec := range ls {
+				got[i] = fmt.Sprintf("%s|%s|%s", spec.HostIp, spec.HostPort, spec.Key())
+			}
+
+			if !slices.Equal(got, c.want) {
+				t.Fatalf("got %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestParsePortBindSpecInvalid(test *testing.T) {
+	var cases = []string{
+		"",
+		"0",
+		"65536",
+		"http",
+		"80/icmp",
+		"1:2:3:4",
+		"8000-8001:80-82",
+		"90-80",
+		"[::1]:80",
+		"[::1",
+	}
+
+	for _, raw := range cases {
+		test.Run(raw, func(t *testing.T) {
+			var ls, err = ParsePortBindSpec(raw)
+			if err == nil {
+				t.Fatalf("expected error, got %d specs", len(ls))
+			}
+
+			if !isErrorCode(err, ErrInvalidPortBindSpec) {
+				t.Fatalf("unexpected error code: %s", err.Error())
+			}
+		})
+	}
+}
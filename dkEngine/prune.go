@@ -0,0 +1,206 @@
+package dkEngine
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type PruneOptions struct {
+	// Until 생성된지 Until 이상 지난 대상만 삭제한다
+	Until time.Duration
+	// Labels 라벨이 있는 대상만 삭제한다, "key" 또는 "key=value"
+	Labels []string
+	// ExcludeLabels 라벨이 있는 대상은 삭제하지 않는다, "key" 또는 "key=value"
+	ExcludeLabels []string
+	// All images: 태그가 있어도 사용하지 않는 이미지를 모두 삭제한다
+	// volumes: 이름이 있는 볼륨도 삭제한다
+	// build cache: 내부 캐시까지 모두 삭제한다
+	All bool
+}
+
+func (x *PruneOptions) filters() Filters {
+	var filters = Filters{}
+	if x.Until != 0 {
+		filters.Add("until", x.Until.String())
+	}
+	filters.Add("label", x.Labels...)
+	filters.Add("label!", x.ExcludeLabels...)
+	return filters
+}
+
+type PruneContainersReport struct {
+	ContainersDeleted []string `json:"ContainersDeleted"`
+	SpaceReclaimed    int64    `json:"SpaceReclaimed"`
+}
+
+type PruneImagesReport struct {
+	ImagesDeleted  []*RemoveImageResponse `json:"ImagesDeleted"`
+	SpaceReclaimed int64                  `json:"SpaceReclaimed"`
+}
+
+type PruneNetworksReport struct {
+	NetworksDeleted []string `json:"NetworksDeleted"`
+}
+
+type PruneVolumesReport struct {
+	VolumesDeleted []string `json:"VolumesDeleted"`
+	SpaceReclaimed int64    `json:"SpaceReclaimed"`
+}
+
+type PruneBuildCacheReport struct {
+	CachesDeleted  []string `json:"CachesDeleted"`
+	SpaceReclaimed int64    `json:"SpaceReclaimed"`
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// PruneContainers
+// 정지된 컨테이너를 삭제한다
+func PruneContainers(
+	host string,
+	opts *PruneOptions,
+) (res *PruneContainersReport, err error) {
+	if opts == nil {
+		opts = &PruneOptions{}
+	}
+
+	res = &PruneContainersReport{}
+	err = prune(host, "/containers/prune", opts.filters(), nil, res)
+	return
+}
+
+// PruneImages
+// 태그가 없는 이미지를 삭제한다, All 이면 컨테이너가 사용하지 않는 모든 이미지를 삭제한다
+func PruneImages(
+	host string,
+	opts *PruneOptions,
+) (res *PruneImagesReport, err error) {
+	if opts == nil {
+		opts = &PruneOptions{}
+	}
+
+	var filters = opts.filters()
+	filters.Add("dangling", strconv.FormatBool(!opts.All))
+
+	res = &PruneImagesReport{}
+	err = prune(host, "/images/prune", filters, nil, res)
+	return
+}
+
+// PruneNetworks
+// 컨테이너가 연결되지 않은 네트워크를 삭제한다
+func PruneNetworks(
+	host string,
+	opts *PruneOptions,
+) (res *PruneNetworksReport, err error) {
+	if opts == nil {
+		opts = &PruneOptions{}
+	}
+
+	res = &PruneNetworksReport{}
+	err = prune(host, "/networks/prune", opts.filters(), nil, res)
+	return
+}
+
+// PruneVolumes
+// 컨테이너가 사용하지 않는 익명 볼륨을 삭제한다, All 이면 이름이 있는 볼륨도 삭제한다
+// 볼륨은 until 필터를 지원하지 않는다
+func PruneVolumes(
+	host string,
+	opts *PruneOptions,
+) (res *PruneVolumesReport, err error) {
+	if opts == nil {
+		opts = &PruneOptions{}
+	}
+
+	var filters = Filters{}
+	filters.Add("label", opts.Labels...)
+	filters.Add("label!", opts.ExcludeLabels...)
+	if opts.All {
+		filters.Add("all", "true")
+	}
+
+	res = &PruneVolumesReport{}
+	err = prune(host, "/volumes/prune", filters, nil, res)
+	return
+}
+
+// PruneBuildCache
+// 빌드 캐시를 삭제한다, 빌드 캐시는 label 필터를 지원하지 않는다
+func PruneBuildCache(
+	host string,
+	opts *PruneOptions,
+) (res *PruneBuildCacheReport, err error) {
+	if opts == nil {
+		opts = &PruneOptions{}
+	}
+
+	var filters = Filters{}
+	if opts.Until != 0 {
+		filters.Add("until", opts.Until.String())
+	}
+
+	var values = url.Values{}
+	values.Set("all", strconv.FormatBool(opts.All))
+
+	res = &PruneBuildCacheReport{}
+	err = prune(host, "/build/prune", filters, values, res)
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+func prune(
+	host string,
+	path string,
+	filters Filters,
+	values url.Values,
+	res any,
+) (err error) {
+	if values == nil {
+		values = url.Values{}
+	}
+
+	var strFilters string
+	if strFilters, err = filters.Encode(); err != nil {
+		return
+	}
+
+	if strFilters != "" {
+		values.Set("filters", strFilters)
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%s%s?%s", host, path, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Minute * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
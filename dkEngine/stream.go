@@ -0,0 +1,80 @@
+package dkEngine
+
+import (
+	"encoding/json"
+	"github.com/d3v-friends/go-tools/fnError"
+	"io"
+)
+
+// JsonMessage
+// pull, push, build, load 등의 작업 결과로 스트림 되는 메시지
+// 응답 코드가 200 이어도 ErrorDetail 로 에러가 전달될 수 있다
+type JsonMessage struct {
+	Id             string              `json:"id,omitempty"`
+	Status         string              `json:"status,omitempty"`
+	Progress       string              `json:"progress,omitempty"`
+	ProgressDetail *JsonProgressDetail `json:"progressDetail,omitempty"`
+	Stream         string              `json:"stream,omitempty"`
+	Error          string              `json:"error,omitempty"`
+	ErrorDetail    *JsonErrorDetail    `json:"errorDetail,omitempty"`
+	Aux            json.RawMessage     `json:"aux,omitempty"`
+}
+
+type JsonProgressDetail struct {
+	Current int64 `json:"current,omitempty"`
+	Total   int64 `json:"total,omitempty"`
+}
+
+type JsonErrorDetail struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ProgressFunc
+// 스트림 메시지를 받을 때마다 호출된다, 레이어별 진행 상황은 Id 로 구분한다
+type ProgressFunc func(msg *JsonMessage)
+
+// readJsonMessages
+// 스트림을 끝까지 읽으며 메시지마다 onProgress 를 호출하고, 에러 메시지가 있으면 에러를 반환한다
+// onAux 는 aux 가 있는 메시지마다 호출된다 (push 결과 digest, build 결과 image id 등)
+func readJsonMessages(
+	reader io.Reader,
+	onProgress ProgressFunc,
+	onAux func(aux json.RawMessage) error,
+) (err error) {
+	var decoder = json.NewDecoder(reader)
+	for {
+		var msg = &JsonMessage{}
+		if err = decoder.Decode(msg); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+			return
+		}
+
+		if onProgress != nil {
+			onProgress(msg)
+		}
+
+		if msg.ErrorDetail != nil && msg.ErrorDetail.Message != "" {
+			err = fnError.NewFields(ErrStreamMessage, map[string]any{
+				"code":    msg.ErrorDetail.Code,
+				"message": msg.ErrorDetail.Message,
+			})
+			return
+		}
+
+		if msg.Error != "" {
+			err = fnError.NewFields(ErrStreamMessage, map[string]any{
+				"message": msg.Error,
+			})
+			return
+		}
+
+		if onAux != nil && len(msg.Aux) != 0 {
+			if err = onAux(msg.Aux); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package dkEngine
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"io"
+	"net/http"
+	"time"
+)
+
+type EngineInfo struct {
+	Id                string `json:"ID"`
+	Name              string `json:"Name"`
+	ServerVersion     string `json:"ServerVersion"`
+	Containers        int64  `json:"Containers"`
+	ContainersRunning int64  `json:"ContainersRunning"`
+	ContainersPaused  int64  `json:"ContainersPaused"`
+	ContainersStopped int64  `json:"ContainersStopped"`
+	Images            int64  `json:"Images"`
+	// Driver 스토리지 드라이버 ex) overlay2
+	Driver        string      `json:"Driver"`
+	DriverStatus  [][2]string `json:"DriverStatus"`
+	DockerRootDir string      `json:"DockerRootDir"`
+	LoggingDriver string      `json:"LoggingDriver"`
+	// CgroupDriver cgroupfs, systemd, none
+	CgroupDriver string `json:"CgroupDriver"`
+	// CgroupVersion "1" 또는 "2"
+	CgroupVersion      string            `json:"CgroupVersion"`
+	MemoryLimit        bool              `json:"MemoryLimit"`
+	SwapLimit          bool              `json:"SwapLimit"`
+	KernelVersion      string            `json:"KernelVersion"`
+	OperatingSystem    string            `json:"OperatingSystem"`
+	OSVersion          string            `json:"OSVersion"`
+	OSType             string            `json:"OSType"`
+	Architecture       string            `json:"Architecture"`
+	NCPU               int64             `json:"NCPU"`
+	MemTotal           int64             `json:"MemTotal"`
+	IndexServerAddress string            `json:"IndexServerAddress"`
+	DefaultRuntime     string            `json:"DefaultRuntime"`
+	Runtimes           map[string]any    `json:"Runtimes"`
+	SecurityOptions    []string          `json:"SecurityOptions"`
+	Labels             []string          `json:"Labels"`
+	ExperimentalBuild  bool              `json:"ExperimentalBuild"`
+	HttpProxy          string            `json:"HttpProxy"`
+	HttpsProxy         string            `json:"HttpsProxy"`
+	NoProxy            string            `json:"NoProxy"`
+	SystemTime         time.Time         `json:"SystemTime"`
+	Warnings           []string          `json:"Warnings"`
+	Plugins            map[string]any    `json:"Plugins"`
+	GenericResources   []map[string]any  `json:"GenericResources"`
+	RegistryConfig     map[string]any    `json:"RegistryConfig"`
+	Swarm              map[string]any    `json:"Swarm"`
+	ContainerdCommit   map[string]string `json:"ContainerdCommit"`
+	RuncCommit         map[string]string `json:"RuncCommit"`
+}
+
+// Info
+// 도커 엔진의 버전, 스토리지 드라이버, cpu, 메모리, cgroup 정보를 조회한다
+func Info(
+	host string,
+) (res *EngineInfo, err error) {
+	res = &EngineInfo{}
+	err = getSystem(host, "/info", res)
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type EngineVersion struct {
+	Platform      *VersionPlatform    `json:"Platform"`
+	Components    []*VersionComponent `json:"Components"`
+	Version       string              `json:"Version"`
+	ApiVersion    string              `json:"ApiVersion"`
+	MinAPIVersion string              `json:"MinAPIVersion"`
+	GitCommit     string              `json:"GitCommit"`
+	GoVersion     string              `json:"GoVersion"`
+	Os            string              `json:"Os"`
+	Arch          string              `json:"Arch"`
+	KernelVersion string              `json:"KernelVersion"`
+	Experimental  bool                `json:"Experimental"`
+	BuildTime     string              `json:"BuildTime"`
+}
+
+type VersionPlatform struct {
+	Name string `json:"Name"`
+}
+
+// VersionComponent
+// Engine, containerd, runc, docker-init 등
+type VersionComponent struct {
+	Name    string         `json:"Name"`
+	Version string         `json:"Version"`
+	Details map[string]any `json:"Details"`
+}
+
+func Version(
+	host string,
+) (res *EngineVersion, err error) {
+	res = &EngineVersion{}
+	err = getSystem(host, "/version", res)
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type DiskUsageReport struct {
+	// LayersSize 이미지 레이어가 사용하는 byte, 이미지끼리 공유하는 레이어는 한번만 계산한다
+	LayersSize int64       `json:"LayersSize"`
+	Images     Images      `json:"Images"`
+	Containers Containers  `json:"Containers"`
+	Volumes    Volumes     `json:"Volumes"`
+	BuildCache BuildCaches `json:"BuildCache"`
+}
+
+type BuildCache struct {
+	Id          string    `json:"ID"`
+	Parents     []string  `json:"Parents"`
+	Type        string    `json:"Type"`
+	Description string    `json:"Description"`
+	InUse       bool      `json:"InUse"`
+	Shared      bool      `json:"Shared"`
+	Size        int64     `json:"Size"`
+	CreatedAt   time.Time `json:"CreatedAt"`
+	LastUsedAt  time.Time `json:"LastUsedAt"`
+	UsageCount  int64     `json:"UsageCount"`
+}
+
+type BuildCaches []*BuildCache
+
+// ContainersSize
+// 컨테이너 쓰기 레이어의 합, 이미지 크기는 포함하지 않는다
+func (x *DiskUsageReport) ContainersSize() (size int64) {
+	for _, container := range x.Containers {
+		size += container.SizeRw
+	}
+	return
+}
+
+// VolumesSize
+// 크기를 계산할 수 없는 볼륨 (-1) 은 제외한다
+func (x *DiskUsageReport) VolumesSize() (size int64) {
+	for _, volume := range x.Volumes {
+		if volume.UsageData == nil || volume.UsageData.Size < 0 {
+			continue
+		}
+		size += volume.UsageData.Size
+	}
+	return
+}
+
+// BuildCacheSize
+// 다른 캐시와 공유하는 레코드는 한번만 계산한다
+func (x *DiskUsageReport) BuildCacheSize() (size int64) {
+	for _, cache := range x.BuildCache {
+		if cache.Shared {
+			continue
+		}
+		size += cache.Size
+	}
+	return
+}
+
+// TotalSize
+// 이미지 레이어, 컨테이너 쓰기 레이어, 볼륨, 빌드 캐시의 합
+func (x *DiskUsageReport) TotalSize() int64 {
+	return x.LayersSize + x.ContainersSize() + x.VolumesSize() + x.BuildCacheSize()
+}
+
+// DiskUsage
+// 이미지, 컨테이너, 볼륨, 빌드 캐시가 사용하는 디스크 용량을 조회한다
+// 볼륨 크기를 계산하므로 오래 걸릴 수 있다
+func DiskUsage(
+	host string,
+) (res *DiskUsageReport, err error) {
+	res = &DiskUsageReport{}
+	err = getSystem(host, "/system/df", res)
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+func getSystem(
+	host string,
+	path string,
+	res any,
+) (err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s%s", host, path),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Minute * 5,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
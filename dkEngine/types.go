@@ -3,13 +3,28 @@ package dkEngine
 import (
 	"encoding/base64"
 	"encoding/json"
+	"github.com/d3v-friends/go-tools/fnError"
 	"github.com/d3v-friends/go-tools/fnPointer"
+	"strconv"
 	"strings"
 	"time"
 )
 
 const (
 	ErrAlreadyHasSameContainerName = "already_has_same_container_name"
+	ErrInvalidPortBindSpec         = "invalid_port_bind_spec"
+	ErrNotFoundPortBinding         = "not_found_port_binding"
+	ErrInvalidEnvFile              = "invalid_env_file"
+	ErrNotFoundImage               = "not_found_image"
+	ErrDeployRolledBack            = "deploy_rolled_back"
+	ErrDeployRequiresDowntime      = "deploy_requires_downtime"
+	ErrContainerNotHealthy         = "container_not_healthy"
+	ErrStreamMessage               = "stream_message"
+	ErrInvalidGcPolicy             = "invalid_gc_policy"
+	ErrAlreadyHasSameNetworkName   = "already_has_same_network_name"
+	ErrNotFoundNetwork             = "not_found_network"
+	ErrNotFoundVolume              = "not_found_volume"
+	ErrVolumeInUse                 = "volume_in_use"
 )
 
 const (
@@ -29,20 +44,48 @@ func (x Platform) String() string {
 }
 
 type Network struct {
-	Name       string         `json:"Name"`
-	Id         string         `json:"Tag"`
-	Created    time.Time      `json:"Created"`
-	Scope      string         `json:"Scope"`
-	Driver     string         `json:"Driver"`
-	EnableIPv6 bool           `json:"EnableIPv6"`
-	IPAM       map[string]any `json:"IPAM"`
-	Internal   bool           `json:"Internal"`
-	Attachable bool           `json:"Attachable"`
-	ConfigForm map[string]any `json:"ConfigForm"`
-	ConfigOnly bool           `json:"ConfigOnly"`
-	Containers map[string]any `json:"Containers"`
-	Options    map[string]any `json:"Options"`
-	Labels     map[string]any `json:"Labels"`
+	Name       string                       `json:"Name"`
+	Id         string                       `json:"Id"`
+	Created    time.Time                    `json:"Created"`
+	Scope      string                       `json:"Scope"`
+	Driver     string                       `json:"Driver"`
+	EnableIPv6 bool                         `json:"EnableIPv6"`
+	IPAM       *IPAM                        `json:"IPAM"`
+	Internal   bool                         `json:"Internal"`
+	Attachable bool                         `json:"Attachable"`
+	Ingress    bool                         `json:"Ingress"`
+	ConfigFrom *NetworkConfigFrom           `json:"ConfigFrom"`
+	ConfigOnly bool                         `json:"ConfigOnly"`
+	Containers map[string]*NetworkContainer `json:"Containers"`
+	Options    map[string]string            `json:"Options"`
+	Labels     map[string]string            `json:"Labels"`
+}
+
+type IPAM struct {
+	Driver  string            `json:"Driver,omitempty"`
+	Config  []*IPAMConfig     `json:"Config,omitempty"`
+	Options map[string]string `json:"Options,omitempty"`
+}
+
+type IPAMConfig struct {
+	Subnet             string            `json:"Subnet,omitempty"`
+	IPRange            string            `json:"IPRange,omitempty"`
+	Gateway            string            `json:"Gateway,omitempty"`
+	AuxiliaryAddresses map[string]string `json:"AuxiliaryAddresses,omitempty"`
+}
+
+type NetworkConfigFrom struct {
+	Network string `json:"Network"`
+}
+
+// NetworkContainer
+// 네트워크에 연결된 컨테이너의 endpoint, 목록 조회에서는 비어있고 InspectNetwork 에서만 채워진다
+type NetworkContainer struct {
+	Name        string `json:"Name"`
+	EndpointID  string `json:"EndpointID"`
+	MacAddress  string `json:"MacAddress"`
+	IPv4Address string `json:"IPv4Address"`
+	IPv6Address string `json:"IPv6Address"`
 }
 
 type Networks []*Network
@@ -57,7 +100,12 @@ type Container struct {
 	Ports      []*ContainerPort  `json:"Ports"`
 	Labels     map[string]string `json:"Labels"`
 	State      string            `json:"State"`
+	Status     string            `json:"Status"`
 	HostConfig map[string]string `json:"HostConfig"`
+	// SizeRw 컨테이너가 쓰기 레이어에 생성, 변경한 byte, 디스크 사용량 조회 (/system/df) 에서만 채워진다
+	SizeRw int64 `json:"SizeRw"`
+	// SizeRootFs 이미지를 포함한 컨테이너 전체 byte, 디스크 사용량 조회 (/system/df) 에서만 채워진다
+	SizeRootFs int64 `json:"SizeRootFs"`
 }
 
 type Names []string
@@ -83,15 +131,41 @@ type Containers []*Container
 // CreateContainerRequest
 // https://docs.docker.com/reference/api/engine/version/v1.47/#tag/Container/operation/ContainerCreate
 type CreateContainerRequest struct {
-	Cmd              []string          `json:"Cmd,omitempty"`
-	Hostname         *string           `json:"ApiHost,omitempty"`
-	User             *string           `json:"User,omitempty"`
-	Env              []string          `json:"Env,omitempty"`
-	Image            *string           `json:"Image,omitempty"`
-	Volumes          map[string]string `json:"Volumes,omitempty"`
-	HostConfig       *HostConfig       `json:"HostConfig,omitempty"`
-	ExposedPorts     ExposedPorts      `json:"ExposedPorts,omitempty"`
-	NetworkingConfig *NetworkingConfig `json:"NetworkingConfig,omitempty"`
+	Cmd              []string            `json:"Cmd,omitempty"`
+	Entrypoint       []string            `json:"Entrypoint,omitempty"`
+	Hostname         *string             `json:"Hostname,omitempty"`
+	Domainname       *string             `json:"Domainname,omitempty"`
+	User             *string             `json:"User,omitempty"`
+	WorkingDir       *string             `json:"WorkingDir,omitempty"`
+	Env              []string            `json:"Env,omitempty"`
+	Labels           map[string]string   `json:"Labels,omitempty"`
+	Image            *string             `json:"Image,omitempty"`
+	StopSignal       *string             `json:"StopSignal,omitempty"`
+	StopTimeout      *int                `json:"StopTimeout,omitempty"`
+	Volumes          map[string]struct{} `json:"Volumes,omitempty"`
+	Healthcheck      *HealthConfig       `json:"Healthcheck,omitempty"`
+	AttachStdin      bool                `json:"AttachStdin,omitempty"`
+	AttachStdout     bool                `json:"AttachStdout,omitempty"`
+	AttachStderr     bool                `json:"AttachStderr,omitempty"`
+	Tty              bool                `json:"Tty,omitempty"`
+	OpenStdin        bool                `json:"OpenStdin,omitempty"`
+	StdinOnce        bool                `json:"StdinOnce,omitempty"`
+	HostConfig       *HostConfig         `json:"HostConfig,omitempty"`
+	ExposedPorts     ExposedPorts        `json:"ExposedPorts,omitempty"`
+	NetworkingConfig *NetworkingConfig   `json:"NetworkingConfig,omitempty"`
+}
+
+// HealthConfig
+// Test: [] 이미지의 설정을 따른다, ["NONE"] healthcheck 를 끈다
+// ["CMD", args...] 명령을 직접 실행한다, ["CMD-SHELL", command] 쉘로 실행한다
+// 시간 값이 0 이면 도커의 기본값을 사용한다
+type HealthConfig struct {
+	Test          []string      `json:"Test,omitempty"`
+	Interval      time.Duration `json:"Interval,omitempty"`
+	Timeout       time.Duration `json:"Timeout,omitempty"`
+	StartPeriod   time.Duration `json:"StartPeriod,omitempty"`
+	StartInterval time.Duration `json:"StartInterval,omitempty"`
+	Retries       int           `json:"Retries,omitempty"`
 }
 
 type CreateContainerResponse struct {
@@ -130,11 +204,74 @@ type EndpointIPAMConfig struct {
 type ExposedPorts map[string]map[string]string
 
 type HostConfig struct {
-	LogConfig    *LogConfig   `json:"LogConfig,omitempty"`
-	PortBindings PortBindings `json:"PortBindings,omitempty"`
-	NetworkMode  *string      `json:"NetworkMode,omitempty"`
-	Binds        []string     `json:"Binds,omitempty"`
-	Privileged   *bool        `json:"Privileged,omitempty"`
+	LogConfig         *LogConfig        `json:"LogConfig,omitempty"`
+	PortBindings      PortBindings      `json:"PortBindings,omitempty"`
+	NetworkMode       *string           `json:"NetworkMode,omitempty"`
+	Binds             []string          `json:"Binds,omitempty"`
+	Privileged        *bool             `json:"Privileged,omitempty"`
+	CapAdd            []string          `json:"CapAdd,omitempty"`
+	CapDrop           []string          `json:"CapDrop,omitempty"`
+	Devices           []*DeviceMapping  `json:"Devices,omitempty"`
+	DeviceCgroupRules []string          `json:"DeviceCgroupRules,omitempty"`
+	Sysctls           map[string]string `json:"Sysctls,omitempty"`
+	SecurityOpt       []string          `json:"SecurityOpt,omitempty"`
+	ReadonlyRootfs    *bool             `json:"ReadonlyRootfs,omitempty"`
+	GroupAdd          []string          `json:"GroupAdd,omitempty"`
+	UsernsMode        *string           `json:"UsernsMode,omitempty"`
+	RestartPolicy     *RestartPolicy    `json:"RestartPolicy,omitempty"`
+	AutoRemove        *bool             `json:"AutoRemove,omitempty"`
+	Init              *bool             `json:"Init,omitempty"`
+	Runtime           *string           `json:"Runtime,omitempty"`
+	Mounts            []*Mount          `json:"Mounts,omitempty"`
+	VolumesFrom       []string          `json:"VolumesFrom,omitempty"`
+	Tmpfs             map[string]string `json:"Tmpfs,omitempty"`
+	ExtraHosts        []string          `json:"ExtraHosts,omitempty"`
+	Dns               []string          `json:"Dns,omitempty"`
+	DnsOptions        []string          `json:"DnsOptions,omitempty"`
+	DnsSearch         []string          `json:"DnsSearch,omitempty"`
+	PidMode           *string           `json:"PidMode,omitempty"`
+	IpcMode           *string           `json:"IpcMode,omitempty"`
+	ShmSize           int64             `json:"ShmSize,omitempty"`
+	Memory            int64             `json:"Memory,omitempty"`
+	MemoryReservation int64             `json:"MemoryReservation,omitempty"`
+	MemorySwap        int64             `json:"MemorySwap,omitempty"`
+	NanoCpus          int64             `json:"NanoCpus,omitempty"`
+	CpuShares         int64             `json:"CpuShares,omitempty"`
+	CpusetCpus        string            `json:"CpusetCpus,omitempty"`
+	PidsLimit         *int64            `json:"PidsLimit,omitempty"`
+	Ulimits           []*Ulimit         `json:"Ulimits,omitempty"`
+}
+
+// RestartPolicy
+// Name: "", "no", "always", "unless-stopped", "on-failure"
+type RestartPolicy struct {
+	Name              string `json:"Name"`
+	MaximumRetryCount int    `json:"MaximumRetryCount"`
+}
+
+// Mount
+// Type: bind, volume, tmpfs, npipe, cluster
+type Mount struct {
+	Type          string         `json:"Type"`
+	Source        string         `json:"Source,omitempty"`
+	Target        string         `json:"Target"`
+	ReadOnly      bool           `json:"ReadOnly,omitempty"`
+	Consistency   string         `json:"Consistency,omitempty"`
+	BindOptions   map[string]any `json:"BindOptions,omitempty"`
+	VolumeOptions map[string]any `json:"VolumeOptions,omitempty"`
+	TmpfsOptions  map[string]any `json:"TmpfsOptions,omitempty"`
+}
+
+type Ulimit struct {
+	Name string `json:"Name"`
+	Soft int64  `json:"Soft"`
+	Hard int64  `json:"Hard"`
+}
+
+type DeviceMapping struct {
+	PathOnHost        string `json:"PathOnHost"`
+	PathInContainer   string `json:"PathInContainer"`
+	CgroupPermissions string `json:"CgroupPermissions"`
 }
 
 type LogConfig struct {
@@ -154,37 +291,53 @@ type PortBindings map[string][]*PortBinding
 // 추후 다른 내용이 필요시 다음 문서에서 찾아보기
 // https://docker-docs.uclv.cu/engine/api/v1.40/#operation/ContainerInspect
 type ContainerInspection struct {
-	Id             string                     `json:"Id"`
-	Created        *string                    `json:"Created"`
-	Path           string                     `json:"Path"`
-	Args           []string                   `json:"Args"`
-	State          *ContainerInspectionState  `json:"State"`
-	Image          string                     `json:"Image"`
-	ResolvConfPath string                     `json:"ResolvConfPath"`
-	HostnamePath   string                     `json:"HostnamePath"`
-	HostsPath      string                     `json:"HostsPath"`
-	LogPath        string                     `json:"LogPath"`
-	Name           string                     `json:"Name"`
-	RestartCount   int                        `json:"RestartCount"`
-	Driver         string                     `json:"Driver"`
-	Platform       string                     `json:"Platform"`
-	MountLabel     string                     `json:"MountLabel"`
-	ProcessLabel   string                     `json:"ProcessLabel"`
-	Config         *ContainerInspectionConfig `json:"Config"`
+	Id              string                              `json:"Id"`
+	Created         *string                             `json:"Created"`
+	Path            string                              `json:"Path"`
+	Args            []string                            `json:"Args"`
+	State           *ContainerInspectionState           `json:"State"`
+	Image           string                              `json:"Image"`
+	ResolvConfPath  string                              `json:"ResolvConfPath"`
+	HostnamePath    string                              `json:"HostnamePath"`
+	HostsPath       string                              `json:"HostsPath"`
+	LogPath         string                              `json:"LogPath"`
+	Name            string                              `json:"Name"`
+	RestartCount    int                                 `json:"RestartCount"`
+	Driver          string                              `json:"Driver"`
+	Platform        string                              `json:"Platform"`
+	MountLabel      string                              `json:"MountLabel"`
+	ProcessLabel    string                              `json:"ProcessLabel"`
+	Config          *ContainerInspectionConfig          `json:"Config"`
+	HostConfig      *HostConfig                         `json:"HostConfig"`
+	NetworkSettings *ContainerInspectionNetworkSettings `json:"NetworkSettings"`
+}
+
+type ContainerInspectionNetworkSettings struct {
+	Ports    PortBindings                 `json:"Ports"`
+	Networks map[string]*EndpointSettings `json:"Networks"`
 }
 
 type ContainerInspectionConfig struct {
-	Hostname     string   `json:"Hostname"`
-	Domainname   string   `json:"Domainname"`
-	User         string   `json:"User"`
-	AttachStdin  bool     `json:"AttachStdin"`
-	AttachStdout bool     `json:"AttachStdout"`
-	AttachStderr bool     `json:"AttachStderr"`
-	Tty          bool     `json:"Tty"`
-	OpenStdin    bool     `json:"OpenStdin"`
-	StdinOnce    bool     `json:"StdinOnce"`
-	Env          []string `json:"Env"`
-	Cmd          []string `json:"Cmd"`
+	Hostname     string              `json:"Hostname"`
+	Domainname   string              `json:"Domainname"`
+	User         string              `json:"User"`
+	AttachStdin  bool                `json:"AttachStdin"`
+	AttachStdout bool                `json:"AttachStdout"`
+	AttachStderr bool                `json:"AttachStderr"`
+	Tty          bool                `json:"Tty"`
+	OpenStdin    bool                `json:"OpenStdin"`
+	StdinOnce    bool                `json:"StdinOnce"`
+	Env          []string            `json:"Env"`
+	Cmd          []string            `json:"Cmd"`
+	Image        string              `json:"Image"`
+	Entrypoint   []string            `json:"Entrypoint"`
+	WorkingDir   string              `json:"WorkingDir"`
+	Labels       map[string]string   `json:"Labels"`
+	StopSignal   string              `json:"StopSignal"`
+	StopTimeout  *int                `json:"StopTimeout"`
+	ExposedPorts ExposedPorts        `json:"ExposedPorts"`
+	Volumes      map[string]struct{} `json:"Volumes"`
+	Healthcheck  *HealthConfig       `json:"Healthcheck"`
 }
 
 type ContainerInspectionState struct {
@@ -220,13 +373,54 @@ func (x *ContainerInspection) Env() (m map[string]string) {
 	}
 
 	for _, s := range x.Config.Env {
-		var ls = strings.Split(s, "=")
-		if len(ls) != 2 {
+		var key, value, has = strings.Cut(s, "=")
+		if !has {
+			continue
+		}
+		m[key] = value
+	}
+
+	return
+}
+
+// HostPorts
+// 실제로 할당된 호스트 포트 바인딩 목록
+func (x *ContainerInspection) HostPorts(
+	container uint64,
+	protocol PortProtocol,
+) (ls []*PortBinding) {
+	ls = make([]*PortBinding, 0)
+	if fnPointer.IsNil(x.NetworkSettings) {
+		return
+	}
+
+	for _, binding := range x.NetworkSettings.Ports[portKey(container, protocol)] {
+		if fnPointer.IsNil(binding) || fnPointer.IsNil(binding.HostPort) {
 			continue
 		}
-		m[ls[0]] = ls[1]
+		ls = append(ls, binding)
+	}
+
+	return
+}
+
+// HostPort
+// 컨테이너 포트에 할당된 호스트 포트, 임의 포트로 바인딩한 경우 확인할 때 사용한다
+func (x *ContainerInspection) HostPort(
+	container uint64,
+	protocol PortProtocol,
+) (port uint64, err error) {
+	for _, binding := range x.HostPorts(container, protocol) {
+		if port, err = strconv.ParseUint(*binding.HostPort, 10, 16); err == nil {
+			return
+		}
 	}
 
+	err = fnError.NewFields(ErrNotFoundPortBinding, map[string]any{
+		"id":        x.Id,
+		"container": container,
+		"protocol":  protocol,
+	})
 	return
 }
 
@@ -0,0 +1,392 @@
+package dkEngine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/d3v-friends/go-docker/dkReference"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPanic"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type Volume struct {
+	Name       string            `json:"Name"`
+	Driver     string            `json:"Driver"`
+	Mountpoint string            `json:"Mountpoint"`
+	CreatedAt  string            `json:"CreatedAt"`
+	Status     map[string]any    `json:"Status"`
+	Labels     map[string]string `json:"Labels"`
+	Scope      string            `json:"Scope"`
+	Options    map[string]string `json:"Options"`
+	// UsageData 디스크 사용량 조회 (/system/df) 에서만 채워진다
+	UsageData *VolumeUsageData `json:"UsageData"`
+}
+
+type VolumeUsageData struct {
+	// Size 볼륨이 사용하는 byte, 계산할 수 없으면 -1
+	Size int64 `json:"Size"`
+	// RefCount 볼륨을 사용하는 컨테이너 수, 계산할 수 없으면 -1
+	RefCount int64 `json:"RefCount"`
+}
+
+type Volumes []*Volume
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type CreateVolumeRequest struct {
+	Name       string            `json:"Name,omitempty"`
+	Driver     string            `json:"Driver,omitempty"`
+	DriverOpts map[string]string `json:"DriverOpts,omitempty"`
+	Labels     map[string]string `json:"Labels,omitempty"`
+}
+
+// CreateVolume
+// 같은 이름의 볼륨이 이미 있으면 새로 만들지 않고 기존 볼륨을 반환한다
+func CreateVolume(
+	host string,
+	args *CreateVolumeRequest,
+) (res *Volume, err error) {
+	var body []byte
+	if body, err = json.Marshal(args); err != nil {
+		return
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodPost,
+		fmt.Sprintf("%s/volumes/create", host),
+		bytes.NewReader(body),
+	); err != nil {
+		return
+	}
+
+	request.Header.Set(httpHeaderKeyContentType, httpHeaderValueApplicationJson)
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 201:
+		res = &Volume{}
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+type QueryVolumesOptions struct {
+	// Dangling true 이면 컨테이너가 사용하지 않는 볼륨만, false 이면 사용중인 볼륨만
+	Dangling *bool
+	Drivers  []string
+	// Labels "key" 또는 "key=value"
+	Labels []string
+	Names  []string
+}
+
+type QueryVolumesResponse struct {
+	Volumes  Volumes  `json:"Volumes"`
+	Warnings []string `json:"Warnings"`
+}
+
+func QueryVolumes(
+	host string,
+	opts *QueryVolumesOptions,
+) (ls Volumes, err error) {
+	if opts == nil {
+		opts = &QueryVolumesOptions{}
+	}
+
+	var filters = Filters{}
+	if opts.Dangling != nil {
+		filters.Add("dangling", strconv.FormatBool(*opts.Dangling))
+	}
+	filters.Add("driver", opts.Drivers...)
+	filters.Add("label", opts.Labels...)
+	filters.Add("name", opts.Names...)
+
+	var values = url.Values{}
+
+	var strFilters string
+	if strFilters, err = filters.Encode(); err != nil {
+		return
+	}
+
+	if strFilters != "" {
+		values.Set("filters", strFilters)
+	}
+
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/volumes?%s", host, values.Encode()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		var result = &QueryVolumesResponse{}
+		if err = json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return
+		}
+
+		ls = result.Volumes
+		if ls == nil {
+			ls = make(Volumes, 0)
+		}
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+func InspectVolume(
+	host string,
+	name string,
+) (res *Volume, err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodGet,
+		fmt.Sprintf("%s/volumes/%s", host, name),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 200:
+		res = &Volume{}
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			return
+		}
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundVolume, map[string]any{
+			"volume": name,
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// RemoveVolume
+// 사용중인 볼륨은 삭제할 수 없다, force 는 볼륨 드라이버에서 에러가 나도 도커에서 제거한다
+func RemoveVolume(
+	host string,
+	name string,
+	force bool,
+) (err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodDelete,
+		fmt.Sprintf("%s/volumes/%s?force=%t", host, name, force),
+		nil,
+	); err != nil {
+		return
+	}
+
+	var resp *http.Response
+	if resp, err = (&http.Client{
+		Timeout: time.Second * 30,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch resp.StatusCode {
+	case 204:
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundVolume, map[string]any{
+			"volume": name,
+		})
+		return
+	case 409:
+		err = fnError.NewFields(ErrVolumeInUse, map[string]any{
+			"volume":  name,
+			"message": string(fnPanic.Value(io.ReadAll(resp.Body))),
+		})
+		return
+	default:
+		err = fnError.NewF("%s", fnPanic.Value(io.ReadAll(resp.Body)))
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+const (
+	// defaultVolumeHelperImage 백업, 복구에 사용하는 컨테이너 이미지, 실행하지 않으므로 작은 이미지면 된다
+	defaultVolumeHelperImage = "busybox:latest"
+	volumeHelperMount        = "/volume"
+	volumeHelperLabel        = "go-docker.volume-helper"
+)
+
+type VolumeHelperOptions struct {
+	// Image 볼륨을 마운트할 임시 컨테이너의 이미지, 기본값 busybox:latest
+	// 컨테이너를 실행하지 않으므로 어떤 이미지든 사용할 수 있다
+	Image string
+	// Registries Image 가 로컬에 없을 때 pull 에 사용한다
+	// Image 의 레지스트리 주소와 같은 인증 정보만 사용하며 없으면 인증 없이 pull 한다
+	Registries []Registry
+}
+
+// BackupVolume
+// 볼륨을 마운트한 임시 컨테이너를 만들어 볼륨 내용을 tar 스트림으로 받는다
+// tar 안의 경로는 volume/ 으로 시작하며 RestoreVolume 으로 그대로 복구할 수 있다
+// Close 하면 임시 컨테이너를 삭제한다, ctx 가 종료되면 스트림도 끊긴다
+func BackupVolume(
+	ctx context.Context,
+	host string,
+	volume string,
+	opts *VolumeHelperOptions,
+) (res io.ReadCloser, err error) {
+	var id string
+	if id, err = createVolumeHelper(ctx, host, volume, "backup", opts); err != nil {
+		return
+	}
+
+	var reader io.ReadCloser
+	if reader, err = CopyFromContainer(ctx, host, id, volumeHelperMount); err != nil {
+		_ = Remove(host, id)
+		return
+	}
+
+	res = &volumeHelperReader{
+		ReadCloser: reader,
+		host:       host,
+		id:         id,
+	}
+	return
+}
+
+// RestoreVolume
+// 볼륨을 마운트한 임시 컨테이너를 만들어 BackupVolume 으로 받은 tar 스트림을 볼륨에 풀고 임시 컨테이너를 삭제한다
+// 볼륨에 이미 있는 파일은 덮어쓰며 tar 에 없는 파일은 삭제하지 않는다
+func RestoreVolume(
+	ctx context.Context,
+	host string,
+	volume string,
+	reader io.Reader,
+	opts *VolumeHelperOptions,
+) (err error) {
+	var id string
+	if id, err = createVolumeHelper(ctx, host, volume, "restore", opts); err != nil {
+		return
+	}
+
+	defer func() {
+		if removeErr := Remove(host, id); removeErr != nil && err == nil {
+			err = removeErr
+		}
+	}()
+
+	return CopyToContainer(ctx, host, id, "/", reader)
+}
+
+type volumeHelperReader struct {
+	io.ReadCloser
+	host string
+	id   string
+}
+
+func (x *volumeHelperReader) Close() (err error) {
+	var errs = make([]error, 0)
+	if closeErr := x.ReadCloser.Close(); closeErr != nil {
+		errs = append(errs, closeErr)
+	}
+
+	if removeErr := Remove(x.host, x.id); removeErr != nil {
+		errs = append(errs, removeErr)
+	}
+
+	return fnError.Concat(errs...)
+}
+
+// createVolumeHelper
+// archive api 는 정지된 컨테이너에서도 동작하므로 컨테이너를 실행하지 않는다
+func createVolumeHelper(
+	ctx context.Context,
+	host string,
+	volume string,
+	action string,
+	opts *VolumeHelperOptions,
+) (id string, err error) {
+	opts = fnPointer.Default(opts, VolumeHelperOptions{})
+	var image = opts.Image
+	if image == "" {
+		image = defaultVolumeHelperImage
+	}
+
+	if _, err = InspectVolume(host, volume); err != nil {
+		return
+	}
+
+	if _, err = InspectImage(host, image); err != nil {
+		if !isErrorCode(err, ErrNotFoundImage) {
+			return
+		}
+
+		var ref *dkReference.Reference
+		if ref, err = dkReference.Parse(image); err != nil {
+			return
+		}
+
+		if err = PullWithProgress(ctx, host, image, nil, findRegistry(ref, opts.Registries)...); err != nil {
+			return
+		}
+	}
+
+	return CreateContainer(host, &CreateContainerArgs{
+		Args: &CreateContainerRequest{
+			Image: fnPointer.Make(image),
+			Cmd:   []string{"true"},
+			Labels: map[string]string{
+				volumeHelperLabel: action,
+			},
+			HostConfig: &HostConfig{
+				NetworkMode: fnPointer.Make("none"),
+				Binds:       []string{fmt.Sprintf("%s:%s", volume, volumeHelperMount)},
+			},
+		},
+		containerName: fmt.Sprintf("%s-%s-%s", volume, action, strconv.FormatInt(time.Now().UnixNano(), 36)),
+	})
+}
@@ -0,0 +1,368 @@
+package dkEngine
+
+import (
+	"context"
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"github.com/d3v-friends/go-tools/fnPointer"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WaitStrategy
+// 컨테이너가 준비될 때까지 기다리는 방법
+// ctx 가 종료되거나 준비될 수 없는 상태 (컨테이너 종료 등) 가 되면 에러를 반환한다
+type WaitStrategy func(ctx context.Context, host string, id string) error
+
+const (
+	waitInterval = time.Second
+	waitLogTail  = 50
+)
+
+// Wait
+// strategies 를 순서대로 모두 기다린다
+// 실패하면 컨테이너의 최근 로그를 에러에 포함한다
+func Wait(
+	ctx context.Context,
+	host string,
+	id string,
+	strategies ...WaitStrategy,
+) (err error) {
+	for _, strategy := range strategies {
+		if err = strategy(ctx, host, id); err == nil {
+			continue
+		}
+
+		// ctx 가 종료되어 실패한 경우에도 로그는 가져온다
+		var logs, logErr = Logs(context.WithoutCancel(ctx), host, id, waitLogTail)
+		if logErr != nil {
+			logs = logErr.Error()
+		}
+
+		err = fnError.NewFields(ErrContainerNotHealthy, map[string]any{
+			"id":    id,
+			"error": err.Error(),
+			"logs":  logs,
+		})
+		return
+	}
+
+	return
+}
+
+// WaitHealthy
+// Wait(ctx, host, id, WaitForHealthy()) 와 같다
+func WaitHealthy(
+	ctx context.Context,
+	host string,
+	id string,
+) error {
+	return Wait(ctx, host, id, WaitForHealthy())
+}
+
+// WaitWithTimeout
+// strategy 에 별도의 제한 시간을 둔다
+func WaitWithTimeout(
+	timeout time.Duration,
+	strategy WaitStrategy,
+) WaitStrategy {
+	return func(ctx context.Context, host string, id string) error {
+		var timeoutCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return strategy(timeoutCtx, host, id)
+	}
+}
+
+// WaitForAll
+// strategies 를 순서대로 모두 기다린다
+func WaitForAll(strategies ...WaitStrategy) WaitStrategy {
+	return func(ctx context.Context, host string, id string) (err error) {
+		for _, strategy := range strategies {
+			if err = strategy(ctx, host, id); err != nil {
+				return
+			}
+		}
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// WaitForHealthy
+// 도커 healthcheck 상태가 healthy 가 될 때까지 기다린다
+// healthcheck 가 없는 컨테이너는 실행중이면 준비된 것으로 본다
+func WaitForHealthy() WaitStrategy {
+	return func(ctx context.Context, host string, id string) error {
+		return poll(ctx, func() (done bool, err error) {
+			var inspection *ContainerInspection
+			if inspection, err = inspectRunning(ctx, host, id); err != nil {
+				return
+			}
+
+			var state = inspection.State
+			if !state.Running {
+				return
+			}
+
+			if fnPointer.IsNil(state.Health) {
+				done = true
+				return
+			}
+
+			switch state.Health.Status {
+			case "healthy":
+				done = true
+			case "unhealthy":
+				err = fnError.NewF("health status is unhealthy: failing streak %d", state.Health.FailingStreak)
+			}
+			return
+		})
+	}
+}
+
+// WaitForPort
+// 호스트에 바인딩된 컨테이너의 tcp 포트가 연결을 받을 때까지 기다린다
+// 도커 엔진 host 의 주소로 접속한다
+func WaitForPort(container uint64) WaitStrategy {
+	return func(ctx context.Context, host string, id string) error {
+		return poll(ctx, func() (done bool, err error) {
+			var address string
+			if address, err = publishedAddress(ctx, host, id, container); err != nil {
+				return
+			}
+
+			var dialer = &net.Dialer{Timeout: waitInterval}
+			var conn, dialErr = dialer.DialContext(ctx, "tcp", address)
+			if dialErr != nil {
+				return
+			}
+
+			_ = conn.Close()
+			done = true
+			return
+		})
+	}
+}
+
+// WaitForHttp
+// 호스트에 바인딩된 컨테이너 포트의 path 가 2xx 를 응답할 때까지 기다린다
+func WaitForHttp(
+	container uint64,
+	path string,
+) WaitStrategy {
+	return func(ctx context.Context, host string, id string) error {
+		var client = &http.Client{Timeout: waitInterval * 5}
+		return poll(ctx, func() (done bool, err error) {
+			var address string
+			if address, err = publishedAddress(ctx, host, id, container); err != nil {
+				return
+			}
+
+			var request *http.Request
+			if request, err = http.NewRequestWithContext(
+				ctx,
+				http.MethodGet,
+				fmt.Sprintf("http://%s%s", address, path),
+				nil,
+			); err != nil {
+				return
+			}
+
+			var resp, doErr = client.Do(request)
+			if doErr != nil {
+				return
+			}
+
+			_ = resp.Body.Close()
+			done = 200 <= resp.StatusCode && resp.StatusCode < 300
+			return
+		})
+	}
+}
+
+// WaitForLog
+// 컨테이너 로그에 pattern 과 일치하는 줄이 나올 때까지 기다린다
+// 로그를 한 줄씩 비교하므로 ^, $ 는 줄의 시작과 끝이다
+// 이미 확인한 로그는 다시 받지 않도록 마지막 로그의 시간 이후만 요청한다
+func WaitForLog(pattern *regexp.Regexp) WaitStrategy {
+	return func(ctx context.Context, host string, id string) error {
+		var last time.Time
+		return poll(ctx, func() (done bool, err error) {
+			var inspection *ContainerInspection
+			if inspection, err = inspectRunning(ctx, host, id); err != nil {
+				return
+			}
+
+			var values = url.Values{}
+			values.Set("timestamps", "true")
+			if !last.IsZero() {
+				var since = last.Add(time.Nanosecond)
+				values.Set("since", fmt.Sprintf("%d.%09d", since.Unix(), since.Nanosecond()))
+			}
+
+			var config = fnPointer.Default(inspection.Config, ContainerInspectionConfig{})
+
+			var logs string
+			if logs, err = readLogs(ctx, host, id, values, config.Tty); err != nil {
+				return
+			}
+
+			// 각 줄은 "<RFC3339Nano 시간> <로그>" 형식이다
+			for _, line := range strings.Split(logs, "\n") {
+				var message = strings.TrimSuffix(line, "\r")
+				if timestamp, rest, ok := strings.Cut(message, " "); ok {
+					if t, parseErr := time.Parse(time.RFC3339Nano, timestamp); parseErr == nil {
+						message = rest
+						if t.After(last) {
+							last = t
+						}
+					}
+				}
+
+				if pattern.MatchString(message) {
+					done = true
+					return
+				}
+			}
+			return
+		})
+	}
+}
+
+// WaitForExec
+// 컨테이너 안에서 cmd 가 종료 코드 0 으로 끝날 때까지 반복해서 실행한다
+// exec 요청이 실패해도 준비되지 않은 것으로 보고 ctx 가 종료될 때까지 다시 시도한다
+func WaitForExec(cmd ...string) WaitStrategy {
+	return func(ctx context.Context, host string, id string) (err error) {
+		var lastErr error
+		err = poll(ctx, func() (done bool, err error) {
+			if _, err = inspectRunning(ctx, host, id); err != nil {
+				return
+			}
+
+			var exitCode *int
+			if exitCode, lastErr = execOnce(ctx, host, id, cmd); lastErr != nil {
+				return
+			}
+
+			done = exitCode != nil && *exitCode == 0
+			return
+		})
+
+		if err != nil && lastErr != nil {
+			err = fnError.NewF("%s: last exec error: %s", err.Error(), lastErr.Error())
+		}
+		return
+	}
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// poll
+// fn 이 done 을 반환할 때까지 반복한다, fn 이 에러를 반환하면 즉시 중단한다
+func poll(
+	ctx context.Context,
+	fn func() (done bool, err error),
+) (err error) {
+	var ticker = time.NewTicker(waitInterval)
+	defer ticker.Stop()
+
+	for {
+		var done bool
+		if done, err = fn(); err != nil || done {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// execOnce
+// cmd 를 한번 실행하고 종료 코드를 반환한다, 아직 실행중이면 nil
+func execOnce(
+	ctx context.Context,
+	host string,
+	id string,
+	cmd []string,
+) (exitCode *int, err error) {
+	var exec *ExecResponse
+	if exec, err = Exec(ctx, host, id, &ExecRequest{
+		AttachStdout: fnPointer.Make(true),
+		AttachStderr: fnPointer.Make(true),
+		Cmd:          cmd,
+	}); err != nil {
+		return
+	}
+
+	if _, err = ExecStart(ctx, host, exec.Id, false); err != nil {
+		return
+	}
+
+	var inspection *ExecInspection
+	if inspection, err = InspectExec(ctx, host, exec.Id); err != nil {
+		return
+	}
+
+	if !inspection.Running {
+		exitCode = inspection.ExitCode
+	}
+	return
+}
+
+// inspectRunning
+// 컨테이너가 종료된 경우 더 기다려도 준비될 수 없으므로 에러를 반환한다
+func inspectRunning(
+	ctx context.Context,
+	host string,
+	id string,
+) (inspection *ContainerInspection, err error) {
+	if inspection, err = inspect(ctx, host, id); err != nil {
+		return
+	}
+
+	// 호출하는 곳에서 State 를 바로 사용할 수 있도록 기본값을 채운다
+	inspection.State = fnPointer.Default(inspection.State, ContainerInspectionState{})
+
+	var state = inspection.State
+	if !state.Running && state.Status != "created" {
+		err = fnError.NewF("container is %s: exit code %d", state.Status, state.ExitCode)
+		return
+	}
+
+	return
+}
+
+func publishedAddress(
+	ctx context.Context,
+	host string,
+	id string,
+	container uint64,
+) (address string, err error) {
+	var inspection *ContainerInspection
+	if inspection, err = inspectRunning(ctx, host, id); err != nil {
+		return
+	}
+
+	var port uint64
+	if port, err = inspection.HostPort(container, PortProtocolTcp); err != nil {
+		return
+	}
+
+	var hostUrl *url.URL
+	if hostUrl, err = url.Parse(host); err != nil {
+		return
+	}
+
+	address = net.JoinHostPort(hostUrl.Hostname(), strconv.FormatUint(port, 10))
+	return
+}
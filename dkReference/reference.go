@@ -0,0 +1,223 @@
+// Package dkReference
+// 이미지 이름 (registry/namespace/repo:tag@sha256:...) 을 해석한다
+// distribution 의 문법을 따른다
+// https://github.com/distribution/reference/blob/main/reference.go
+package dkReference
+
+import (
+	"fmt"
+	"github.com/d3v-friends/go-tools/fnError"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+const (
+	ErrInvalidReference = "invalid_reference"
+)
+
+const (
+	DefaultDomain    = "docker.io"
+	DefaultNamespace = "library"
+	DefaultTag       = "latest"
+
+	legacyDefaultDomain = "index.docker.io"
+	nameTotalLengthMax  = 255
+)
+
+var (
+	domainRegexp = regexp.MustCompile(
+		`^(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*|\[[a-fA-F0-9:]+\])(?::[0-9]+)?$`,
+	)
+	pathComponentRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]+)[a-z0-9]+)*$`)
+	tagRegexp           = regexp.MustCompile(`^[\w][\w.-]{0,127}$`)
+	digestRegexp        = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}$`)
+)
+
+type Reference struct {
+	// Domain ex) docker.io, docker.d3v-friends.com, localhost:5000
+	Domain string
+	// Path 레지스트리 api 에서 사용하는 저장소 이름 ex) library/nginx, d3v-friends/api
+	Path string
+	// Tag 없으면 빈 문자열
+	Tag string
+	// Digest ex) sha256:...
+	Digest string
+}
+
+// Parse
+// 도메인이 없으면 docker.io 를, docker.io 의 단일 이름에는 library/ 를 붙인다
+// 태그는 기본값을 붙이지 않으며 필요하면 TagOrDefault 를 사용한다
+func Parse(raw string) (res *Reference, err error) {
+	return parse(raw, DefaultDomain)
+}
+
+// ParseInDomain
+// 도메인이 없는 이름은 domain 레지스트리의 저장소로 해석한다, domain 이 docker.io 가 아니면 library/ 를 붙이지 않는다
+// ex) ParseInDomain("api:v1", "docker.d3v-friends.com") -> docker.d3v-friends.com/api:v1
+func ParseInDomain(raw string, domain string) (res *Reference, err error) {
+	return parse(raw, NormalizeDomain(domain))
+}
+
+// NormalizeDomain
+// 레지스트리 서버 주소를 Reference.Domain 과 비교할 수 있도록 정리한다
+// ex) https://index.docker.io/v1/ -> docker.io, https://localhost:5000 -> localhost:5000
+func NormalizeDomain(address string) string {
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		address = u.Host
+	}
+
+	address = strings.TrimSuffix(address, "/")
+	if address == legacyDefaultDomain || address == "registry-1.docker.io" {
+		address = DefaultDomain
+	}
+
+	return address
+}
+
+func parse(raw string, defaultDomain string) (res *Reference, err error) {
+	var invalid = func(reason string) error {
+		return fnError.NewFields(ErrInvalidReference, map[string]any{
+			"reference": raw,
+			"reason":    reason,
+		})
+	}
+
+	if raw == "" {
+		err = invalid("empty reference")
+		return
+	}
+
+	res = &Reference{}
+
+	var name = raw
+	if idx := strings.Index(name, "@"); idx != -1 {
+		res.Digest = name[idx+1:]
+		name = name[:idx]
+		if !digestRegexp.MatchString(res.Digest) {
+			err = invalid("invalid digest")
+			return
+		}
+	}
+
+	// 태그의 ':' 와 도메인 포트의 ':' 를 구분하기 위해 마지막 '/' 이후에서만 찾는다
+	if idx := strings.LastIndex(name, ":"); idx != -1 && idx > strings.LastIndex(name, "/") {
+		res.Tag = name[idx+1:]
+		name = name[:idx]
+		if !tagRegexp.MatchString(res.Tag) {
+			err = invalid("invalid tag")
+			return
+		}
+	}
+
+	if len(name) > nameTotalLengthMax {
+		err = invalid(fmt.Sprintf("repository name must not be more than %d characters", nameTotalLengthMax))
+		return
+	}
+
+	var domain, path, hasSlash = strings.Cut(name, "/")
+	if !hasSlash || !(strings.ContainsAny(domain, ".:") || domain == "localhost" || strings.ToLower(domain) != domain) {
+		domain = defaultDomain
+		path = name
+	}
+
+	if domain == legacyDefaultDomain {
+		domain = DefaultDomain
+	}
+
+	if !domainRegexp.MatchString(domain) {
+		err = invalid("invalid domain")
+		return
+	}
+
+	if domain == DefaultDomain && !strings.Contains(path, "/") {
+		path = fmt.Sprintf("%s/%s", DefaultNamespace, path)
+	}
+
+	for _, component := range strings.Split(path, "/") {
+		if !pathComponentRegexp.MatchString(component) {
+			if strings.ToLower(component) != component {
+				err = invalid("repository name must be lowercase")
+				return
+			}
+			err = invalid("invalid repository name")
+			return
+		}
+	}
+
+	res.Domain = domain
+	res.Path = path
+	return
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// Name
+// ex) docker.io/library/nginx
+func (x *Reference) Name() string {
+	return fmt.Sprintf("%s/%s", x.Domain, x.Path)
+}
+
+// FamiliarName
+// docker cli 에서 보이는 이름 ex) nginx, d3v-friends/api, docker.d3v-friends.com/api
+func (x *Reference) FamiliarName() string {
+	if x.Domain != DefaultDomain {
+		return x.Name()
+	}
+	return strings.TrimPrefix(x.Path, DefaultNamespace+"/")
+}
+
+// TagOrDefault
+// 태그와 digest 가 모두 없으면 latest
+func (x *Reference) TagOrDefault() string {
+	if x.Tag == "" && x.Digest == "" {
+		return DefaultTag
+	}
+	return x.Tag
+}
+
+// Version
+// 매니페스트를 조회할 때 사용하는 값, digest 가 있으면 digest 가 우선한다
+func (x *Reference) Version() string {
+	if x.Digest != "" {
+		return x.Digest
+	}
+	return x.TagOrDefault()
+}
+
+func (x *Reference) String() string {
+	return x.format(x.Name())
+}
+
+func (x *Reference) FamiliarString() string {
+	return x.format(x.FamiliarName())
+}
+
+func (x *Reference) format(name string) string {
+	if x.Tag != "" {
+		name = fmt.Sprintf("%s:%s", name, x.Tag)
+	}
+
+	if x.Digest != "" {
+		name = fmt.Sprintf("%s@%s", name, x.Digest)
+	}
+
+	return name
+}
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// PullQuery
+// 도커엔진 /images/create 의 fromImage, tag 파라메터
+func (x *Reference) PullQuery() url.Values {
+	var values = url.Values{}
+	values.Set("fromImage", x.Name())
+	values.Set("tag", x.Version())
+	return values
+}
+
+// ManifestPath
+// 레지스트리 api 의 매니페스트 경로 ex) /v2/library/nginx/manifests/latest
+func (x *Reference) ManifestPath() string {
+	return fmt.Sprintf("/v2/%s/manifests/%s", x.Path, x.Version())
+}
@@ -0,0 +1,171 @@
+package dkReference
+
+import (
+	"strings"
+	"testing"
+)
+
+const testDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestParse(test *testing.T) {
+	var cases = []struct {
+		raw      string
+		domain   string
+		path     string
+		tag      string
+		digest   string
+		name     string
+		familiar string
+	}{
+		{"nginx", "docker.io", "library/nginx", "", "", "docker.io/library/nginx", "nginx"},
+		{"nginx:1.25", "docker.io", "library/nginx", "1.25", "", "docker.io/library/nginx", "nginx"},
+		{"d3v-friends/api:latest", "docker.io", "d3v-friends/api", "latest", "", "docker.io/d3v-friends/api", "d3v-friends/api"},
+		{"docker.io/nginx", "docker.io", "library/nginx", "", "", "docker.io/library/nginx", "nginx"},
+		{"index.docker.io/library/nginx", "docker.io", "library/nginx", "", "", "docker.io/library/nginx", "nginx"},
+		{"localhost/api", "localhost", "api", "", "", "localhost/api", "localhost/api"},
+		{"localhost:5000/api:v1", "localhost:5000", "api", "v1", "", "localhost:5000/api", "localhost:5000/api"},
+		{"docker.d3v-friends.com/team/api:v1.2.3", "docker.d3v-friends.com", "team/api", "v1.2.3", "", "docker.d3v-friends.com/team/api", "docker.d3v-friends.com/team/api"},
+		{"[::1]:5000/api", "[::1]:5000", "api", "", "", "[::1]:5000/api", "[::1]:5000/api"},
+		{"nginx@" + testDigest, "docker.io", "library/nginx", "", testDigest, "docker.io/library/nginx", "nginx"},
+		{"nginx:1.25@" + testDigest, "docker.io", "library/nginx", "1.25", testDigest, "docker.io/library/nginx", "nginx"},
+		{"a__b/c-d.e_f", "docker.io", "a__b/c-d.e_f", "", "", "docker.io/a__b/c-d.e_f", "a__b/c-d.e_f"},
+	}
+
+	for _, c := range cases {
+		test.Run(c.raw, func(t *testing.T) {
+			var ref, err = Parse(c.raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if ref.Domain != c.domain || ref.Path != c.path || ref.Tag != c.tag || ref.Digest != c.digest {
+				t.Fatalf("got %+v", ref)
+			}
+
+			if ref.Name() != c.name {
+				t.Fatalf("Name: got %q, want %q", ref.Name(), c.name)
+			}
+
+			if ref.FamiliarName() != c.familiar {
+				t.Fatalf("FamiliarName: got %q, want %q", ref.FamiliarName(), c.familiar)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(test *testing.T) {
+	var cases = []string{
+		"",
+		"Nginx",
+		"nginx:",
+		"nginx:-bad",
+		"nginx@sha256:short",
+		"nginx@" + testDigest + "extra-",
+		"-nginx",
+		"nginx/",
+		"nginx//api",
+		"nginx:" + strings.Repeat("a", 129),
+		strings.Repeat("a", 256),
+		"bad_domain.com:port/api",
+	}
+
+	for _, raw := range cases {
+		test.Run(raw, func(t *testing.T) {
+			if ref, err := Parse(raw); err == nil {
+				t.Fatalf("expected error, got %+v", ref)
+			}
+		})
+	}
+}
+
+func TestReferenceVersion(test *testing.T) {
+	var cases = []struct {
+		raw          string
+		tagOrDefault string
+		version      string
+		str          string
+		manifestPath string
+	}{
+		{"nginx", "latest", "latest", "docker.io/library/nginx", "/v2/library/nginx/manifests/latest"},
+		{"nginx:1.25", "1.25", "1.25", "docker.io/library/nginx:1.25", "/v2/library/nginx/manifests/1.25"},
+		{"nginx@" + testDigest, "", testDigest, "docker.io/library/nginx@" + testDigest, "/v2/library/nginx/manifests/" + testDigest},
+		{"nginx:1.25@" + testDigest, "1.25", testDigest, "docker.io/library/nginx:1.25@" + testDigest, "/v2/library/nginx/manifests/" + testDigest},
+	}
+
+	for _, c := range cases {
+		test.Run(c.raw, func(t *testing.T) {
+			var ref, err = Parse(c.raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if got := ref.TagOrDefault(); got != c.tagOrDefault {
+				t.Fatalf("TagOrDefault: got %q, want %q", got, c.tagOrDefault)
+			}
+
+			if got := ref.Version(); got != c.version {
+				t.Fatalf("Version: got %q, want %q", got, c.version)
+			}
+
+			if got := ref.String(); got != c.str {
+				t.Fatalf("String: got %q, want %q", got, c.str)
+			}
+
+			if got := ref.ManifestPath(); got != c.manifestPath {
+				t.Fatalf("ManifestPath: got %q, want %q", got, c.manifestPath)
+			}
+		})
+	}
+}
+
+func TestParseInDomain(test *testing.T) {
+	var cases = []struct {
+		raw    string
+		domain string
+		name   string
+	}{
+		{"api:v1", "docker.d3v-friends.com", "docker.d3v-friends.com/api"},
+		{"team/api", "https://docker.d3v-friends.com/", "docker.d3v-friends.com/team/api"},
+		{"api", "localhost:5000", "localhost:5000/api"},
+		{"nginx", "https://index.docker.io/v1/", "docker.io/library/nginx"},
+		{"nginx", "registry-1.docker.io", "docker.io/library/nginx"},
+		{"docker.io/nginx", "docker.d3v-friends.com", "docker.io/library/nginx"},
+		{"localhost:5000/api", "docker.d3v-friends.com", "localhost:5000/api"},
+	}
+
+	for _, c := range cases {
+		test.Run(c.raw+" in "+c.domain, func(t *testing.T) {
+			var ref, err = ParseInDomain(c.raw, c.domain)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err.Error())
+			}
+
+			if ref.Name() != c.name {
+				t.Fatalf("Name: got %q, want %q", ref.Name(), c.name)
+			}
+		})
+	}
+}
+
+func TestNormalizeDomain(test *testing.T) {
+	var cases = []struct {
+		address string
+		want    string
+	}{
+		{"docker.io", "docker.io"},
+		{"index.docker.io", "docker.io"},
+		{"registry-1.docker.io", "docker.io"},
+		{"https://index.docker.io/v1/", "docker.io"},
+		{"docker.d3v-friends.com", "docker.d3v-friends.com"},
+		{"docker.d3v-friends.com/", "docker.d3v-friends.com"},
+		{"http://localhost:5000", "localhost:5000"},
+	}
+
+	for _, c := range cases {
+		test.Run(c.address, func(t *testing.T) {
+			if got := NormalizeDomain(c.address); got != c.want {
+				t.Fatalf("got %q, want %q", got, c.want)
+			}
+		})
+	}
+}
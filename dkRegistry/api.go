@@ -3,10 +3,12 @@ package dkRegistry
 import (
 	"encoding/json"
 	"fmt"
+	"github.com/d3v-friends/go-docker/dkReference"
 	"github.com/d3v-friends/go-tools/fnError"
 	"github.com/d3v-friends/go-tools/fnPanic"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -131,3 +133,46 @@ func QueryTags(
 		return
 	}
 }
+
+/* ------------------------------------------------------------------------------------------------------------ */
+
+// HasManifest
+// ref 의 digest, 없으면 태그의 매니페스트를 확인하며 매니페스트가 없으면 ErrNotFoundManifest 를 반환한다
+// ref.Domain 은 보지 않으며 args 레지스트리에 요청한다
+func HasManifest(
+	args Registry,
+	ref *dkReference.Reference,
+) (err error) {
+	var request *http.Request
+	if request, err = http.NewRequest(
+		http.MethodHead,
+		fmt.Sprintf("https://%s%s", args.GetServerAddress(), ref.ManifestPath()),
+		nil,
+	); err != nil {
+		return
+	}
+
+	request.SetBasicAuth(args.GetUsername(), args.GetPassword())
+	request.Header.Set(httpHeaderKeyAccept, strings.Join(manifestMediaTypes, ", "))
+
+	var response *http.Response
+	if response, err = (&http.Client{
+		Timeout: time.Second * 10,
+	}).Do(request); err != nil {
+		return
+	}
+
+	switch response.StatusCode {
+	case 200:
+		return
+	case 404:
+		err = fnError.NewFields(ErrNotFoundManifest, map[string]any{
+			"repository": ref.Path,
+			"reference":  ref.Version(),
+		})
+		return
+	default:
+		err = fnError.NewF("%s: %s", response.Status, fnPanic.Value(io.ReadAll(response.Body)))
+		return
+	}
+}
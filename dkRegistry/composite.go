@@ -1,6 +1,7 @@
 package dkRegistry
 
 import (
+	"github.com/d3v-friends/go-docker/dkReference"
 	"github.com/d3v-friends/go-tools/fnError"
 	"github.com/d3v-friends/go-tools/fnSlice"
 )
@@ -8,6 +9,8 @@ import (
 const (
 	ErrNotFoundRepository = "not_found_repository"
 	ErrNotFoundTag        = "not_found_tag"
+	ErrNotFoundManifest   = "not_found_manifest"
+	ErrMismatchedDomain   = "mismatched_domain"
 )
 
 func HasRepository(
@@ -65,3 +68,42 @@ func HasTag(
 
 	return
 }
+
+// HasImage
+// image 는 registry/namespace/repo:tag@digest 형식이며 태그와 digest 가 모두 없으면 latest 로 확인한다
+// 도메인이 없으면 args 레지스트리의 저장소로 보고, 다른 레지스트리의 이미지면 ErrMismatchedDomain 을 반환한다
+// ex) args 가 docker.d3v-friends.com 일때 api:v1 -> api 저장소의 v1 태그
+// digest 가 있으면 매니페스트가 있는지 확인하고, 태그도 있으면 태그까지 확인한다
+func HasImage(
+	args Registry,
+	image string,
+) (err error) {
+	var domain = dkReference.NormalizeDomain(args.GetServerAddress())
+
+	var ref *dkReference.Reference
+	if ref, err = dkReference.ParseInDomain(image, domain); err != nil {
+		return
+	}
+
+	if ref.Domain != domain {
+		err = fnError.NewFields(ErrMismatchedDomain, map[string]any{
+			"image":  image,
+			"domain": domain,
+		})
+		return
+	}
+
+	if ref.Digest == "" || ref.Tag != "" {
+		if err = HasTag(args, ref.Path, ref.TagOrDefault()); err != nil {
+			return
+		}
+	}
+
+	if ref.Digest != "" {
+		if err = HasManifest(args, ref); err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -2,9 +2,19 @@ package dkRegistry
 
 const (
 	httpHeaderKeyContentType       = "Content-Type"
+	httpHeaderKeyAccept            = "Accept"
 	httpHeaderValueApplicationJson = "application/json"
 )
 
+// manifestMediaTypes
+// 멀티 플랫폼 이미지의 index 와 단일 이미지의 manifest 를 모두 받는다
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
 type Registry interface {
 	GetServerAddress() string
 	GetUsername() string